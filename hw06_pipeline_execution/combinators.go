@@ -0,0 +1,301 @@
+package hw06pipelineexecution
+
+import "time"
+
+// Batch группирует элементы в срезы []interface{} длиной до size.
+// Неполная пачка отправляется, если с момента прихода её первого элемента прошло maxWait
+// (maxWait <= 0 — ждать заполнения) или входной канал закрылся.
+func Batch(size int, maxWait time.Duration, done In) Stage {
+	if size <= 0 {
+		size = 1
+	}
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer close(out)
+
+			batch := make([]interface{}, 0, size)
+			var timeout <-chan time.Time
+			flush := func() bool {
+				timeout = nil
+				if len(batch) == 0 {
+					return true
+				}
+				ok := send(out, batch, done)
+				batch = make([]interface{}, 0, size)
+				return ok
+			}
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-timeout:
+					if !flush() {
+						return
+					}
+				case v, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+					batch = append(batch, v)
+					if len(batch) == 1 && maxWait > 0 {
+						timeout = time.After(maxWait)
+					}
+					if len(batch) >= size && !flush() {
+						return
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// TumblingWindow собирает элементы в неперекрывающиеся окна длительностью size
+// и отправляет каждое непустое окно срезом []interface{}.
+// Паникует при size <= 0 сразу, а не в горутине стадии.
+func TumblingWindow(size time.Duration, done In) Stage {
+	if size <= 0 {
+		panic("hw06pipelineexecution: TumblingWindow: non-positive size")
+	}
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer close(out)
+
+			ticker := time.NewTicker(size)
+			defer ticker.Stop()
+
+			var window []interface{}
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if len(window) > 0 {
+						if !send(out, window, done) {
+							return
+						}
+						window = nil
+					}
+				case v, ok := <-in:
+					if !ok {
+						if len(window) > 0 {
+							send(out, window, done)
+						}
+						return
+					}
+					window = append(window, v)
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// timedItem — элемент скользящего окна с временем поступления.
+type timedItem struct {
+	at time.Time
+	v  interface{}
+}
+
+// SlidingWindow каждые slide отправляет срез элементов, пришедших за последние size.
+// Окно отправляется, только если с прошлой отправки в него что-то добавилось.
+// Паникует при size <= 0 или slide <= 0 сразу, а не в горутине стадии.
+func SlidingWindow(size, slide time.Duration, done In) Stage {
+	if size <= 0 || slide <= 0 {
+		panic("hw06pipelineexecution: SlidingWindow: non-positive size or slide")
+	}
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer close(out)
+
+			ticker := time.NewTicker(slide)
+			defer ticker.Stop()
+
+			var items []timedItem
+			var changed bool
+			emit := func(now time.Time) bool {
+				// Выбрасываем элементы, вышедшие за пределы окна
+				from := now.Add(-size)
+				i := 0
+				for i < len(items) && items[i].at.Before(from) {
+					i++
+				}
+				items = items[i:]
+
+				if !changed || len(items) == 0 {
+					return true
+				}
+				changed = false
+
+				window := make([]interface{}, 0, len(items))
+				for _, item := range items {
+					window = append(window, item.v)
+				}
+				return send(out, window, done)
+			}
+
+			for {
+				select {
+				case <-done:
+					return
+				case now := <-ticker.C:
+					if !emit(now) {
+						return
+					}
+				case v, ok := <-in:
+					if !ok {
+						emit(time.Now())
+						return
+					}
+					items = append(items, timedItem{at: time.Now(), v: v})
+					changed = true
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// Buffer развязывает соседние стадии очередью ёмкостью n.
+// Паникует при n < 0 сразу, а не в горутине стадии.
+func Buffer(n int, done In) Stage {
+	if n < 0 {
+		panic("hw06pipelineexecution: Buffer: negative size")
+	}
+
+	return func(in In) Out {
+		out := make(Bi, n)
+
+		go func() {
+			defer drain(in)
+			defer close(out)
+			for {
+				select {
+				case <-done:
+					return
+				case v, ok := <-in:
+					if !ok || !send(out, v, done) {
+						return
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// Filter пропускает дальше только элементы, для которых pred вернул true.
+func Filter(pred func(v interface{}) bool, done In) Stage {
+	return FlatMap(func(v interface{}) []interface{} {
+		if pred(v) {
+			return []interface{}{v}
+		}
+		return nil
+	}, done)
+}
+
+// FlatMap отправляет дальше все элементы, которые f вернула для входного значения.
+func FlatMap(f func(v interface{}) []interface{}, done In) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer close(out)
+			for {
+				select {
+				case <-done:
+					return
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					for _, res := range f(v) {
+						if !send(out, res, done) {
+							return
+						}
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// Tee возвращает стадию, которая пропускает элементы дальше по конвейеру
+// и одновременно копирует каждый из них в n дополнительных каналов.
+// Все каналы нужно читать: медленный потребитель тормозит весь конвейер.
+// Стадию можно использовать только в одном конвейере.
+func Tee(n int, done In) (Stage, []Out) {
+	sinks := make([]Bi, n)
+	outs := make([]Out, n)
+	for i := range sinks {
+		sinks[i] = make(Bi)
+		outs[i] = sinks[i]
+	}
+
+	stage := func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer func() {
+				for _, sink := range sinks {
+					close(sink)
+				}
+			}()
+			defer close(out)
+			for {
+				select {
+				case <-done:
+					return
+				case v, ok := <-in:
+					if !ok || !send(out, v, done) {
+						return
+					}
+					for _, sink := range sinks {
+						if !send(sink, v, done) {
+							return
+						}
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+
+	return stage, outs
+}
+
+// send отправляет v в out, если раньше не закрылся done.
+func send(out Bi, v interface{}, done In) bool {
+	if isClosed(done) {
+		return false
+	}
+	select {
+	case <-done:
+		return false
+	case out <- v:
+		return true
+	}
+}
@@ -0,0 +1,175 @@
+package hw06pipelineexecution
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombinators(t *testing.T) {
+	produce := func(data []int, delay time.Duration) In {
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for _, v := range data {
+				time.Sleep(delay)
+				in <- v
+			}
+		}()
+		return in
+	}
+
+	collect := func(out Out) []interface{} {
+		result := make([]interface{}, 0)
+		for v := range out {
+			result = append(result, v)
+		}
+		return result
+	}
+
+	t.Run("batch by size", func(t *testing.T) {
+		out := ExecutePipeline(produce([]int{1, 2, 3, 4, 5}, 0), nil, Batch(2, 0, nil))
+
+		require.Equal(t, []interface{}{
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{5},
+		}, collect(out))
+	})
+
+	t.Run("batch by max wait", func(t *testing.T) {
+		// Элементы приходят раз в 30ms, пачка закрывается по таймауту 50ms
+		out := ExecutePipeline(produce([]int{1, 2, 3, 4}, time.Millisecond*30), nil, Batch(10, time.Millisecond*50, nil))
+		result := collect(out)
+
+		require.Greater(t, len(result), 1)
+		flat := make([]interface{}, 0, 4)
+		for _, batch := range result {
+			require.LessOrEqual(t, len(batch.([]interface{})), 2)
+			flat = append(flat, batch.([]interface{})...)
+		}
+		require.Equal(t, []interface{}{1, 2, 3, 4}, flat)
+	})
+
+	t.Run("tumbling window", func(t *testing.T) {
+		out := ExecutePipeline(produce([]int{1, 2, 3, 4, 5, 6}, time.Millisecond*20), nil,
+			TumblingWindow(time.Millisecond*50, nil))
+		result := collect(out)
+
+		require.Greater(t, len(result), 1)
+		flat := make([]interface{}, 0, 6)
+		for _, window := range result {
+			require.NotEmpty(t, window)
+			flat = append(flat, window.([]interface{})...)
+		}
+		require.Equal(t, []interface{}{1, 2, 3, 4, 5, 6}, flat)
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		out := ExecutePipeline(produce([]int{1, 2, 3, 4, 5, 6}, time.Millisecond*20), nil,
+			SlidingWindow(time.Millisecond*60, time.Millisecond*20, nil))
+		result := collect(out)
+
+		require.NotEmpty(t, result)
+		seen := make(map[interface{}]bool)
+		for _, window := range result {
+			items := window.([]interface{})
+			// Окно в 60ms при интервале 20ms не может вместить больше 4 элементов
+			require.LessOrEqual(t, len(items), 4)
+			for _, v := range items {
+				seen[v] = true
+			}
+		}
+		require.Len(t, seen, 6)
+	})
+
+	t.Run("invalid window durations", func(t *testing.T) {
+		require.PanicsWithValue(t, "hw06pipelineexecution: TumblingWindow: non-positive size", func() {
+			TumblingWindow(0, nil)
+		})
+		require.Panics(t, func() { SlidingWindow(time.Second, 0, nil) })
+		require.Panics(t, func() { SlidingWindow(-time.Second, time.Second, nil) })
+	})
+
+	t.Run("invalid buffer size", func(t *testing.T) {
+		require.PanicsWithValue(t, "hw06pipelineexecution: Buffer: negative size", func() {
+			Buffer(-1, nil)
+		})
+		require.NotPanics(t, func() { Buffer(0, nil) })
+	})
+
+	t.Run("buffer decouples stages", func(t *testing.T) {
+		in := make(Bi)
+		out := Buffer(3, nil)(in)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			in <- i
+		}
+		close(in)
+		require.Less(t, int64(time.Since(start)), int64(fault))
+
+		require.Equal(t, []interface{}{0, 1, 2}, collect(out))
+	})
+
+	t.Run("filter and flat map", func(t *testing.T) {
+		even := Filter(func(v interface{}) bool { return v.(int)%2 == 0 }, nil)
+		twice := FlatMap(func(v interface{}) []interface{} { return []interface{}{v, v} }, nil)
+		out := ExecutePipeline(produce([]int{1, 2, 3, 4}, 0), nil, even, twice)
+
+		require.Equal(t, []interface{}{2, 2, 4, 4}, collect(out))
+	})
+
+	t.Run("tee", func(t *testing.T) {
+		tee, sinks := Tee(2, nil)
+		out := ExecutePipeline(produce([]int{1, 2, 3}, 0), nil, tee)
+
+		results := make([][]interface{}, len(sinks)+1)
+		wg := sync.WaitGroup{}
+		wg.Add(len(results))
+		for i, ch := range append([]Out{out}, sinks...) {
+			go func() {
+				defer wg.Done()
+				results[i] = collect(ch)
+			}()
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			require.Equal(t, []interface{}{1, 2, 3}, result)
+		}
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		abortDur := sleepPerStage / 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		// Вход закрывается позже done, поэтому накопленные элементы не должны уйти дальше
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for _, v := range []int{1, 2, 3} {
+				in <- v
+			}
+			<-time.After(sleepPerStage)
+		}()
+
+		start := time.Now()
+		out := ExecutePipeline(in, done,
+			Buffer(1, done),
+			Batch(10, 0, done),
+			TumblingWindow(sleepPerStage, done),
+		)
+		result := collect(out)
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+	})
+}
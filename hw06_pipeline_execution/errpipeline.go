@@ -0,0 +1,127 @@
+package hw06pipelineexecution
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrStage — стадия, которая может сообщить об ошибке обработки элемента.
+type ErrStage struct {
+	Name string
+	Fn   func(v interface{}) (interface{}, error)
+}
+
+// DeadLetter описывает элемент, который не удалось обработать.
+type DeadLetter struct {
+	Stage string      // Имя стадии, на которой произошла ошибка
+	Value interface{} // Входное значение стадии
+	Err   error       // Причина
+}
+
+func (d DeadLetter) Error() string {
+	return fmt.Sprintf("stage %q: %v", d.Stage, d.Err)
+}
+
+func (d DeadLetter) Unwrap() error {
+	return d.Err
+}
+
+// ExecuteErrPipeline запускает стадии, возвращающие (value, error).
+// Упавшие элементы отправляются в канал dead letters, а конвейер продолжает работу.
+// Если maxErrors > 0, после maxErrors ошибок конвейер останавливается так же,
+// как при закрытии done. Оба возвращаемых канала нужно читать до закрытия.
+func ExecuteErrPipeline(in In, done In, maxErrors int, stages ...ErrStage) (Out, <-chan DeadLetter) {
+	deadLetters := make(chan DeadLetter)
+	stop := make(Bi)
+	finished := make(Bi)
+
+	var once sync.Once
+	closeStop := func() { once.Do(func() { close(stop) }) }
+
+	go func() {
+		select {
+		case <-done:
+		case <-finished:
+		}
+		closeStop()
+	}()
+
+	var mu sync.Mutex
+	var errCount int
+	onError := func() {
+		mu.Lock()
+		errCount++
+		limitReached := maxErrors > 0 && errCount >= maxErrors
+		mu.Unlock()
+
+		if limitReached {
+			closeStop()
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	current := in
+	for _, stage := range stages {
+		wg.Add(1)
+		current = errLayerStage(stage, current, stop, deadLetters, onError, &wg)
+	}
+
+	go func() {
+		wg.Wait()
+		close(finished)
+		close(deadLetters)
+	}()
+
+	return current, deadLetters
+}
+
+func errLayerStage(
+	stage ErrStage, current In, stop In, deadLetters chan<- DeadLetter, onError func(), wg *sync.WaitGroup,
+) Out {
+	out := make(Bi)
+
+	go func() {
+		defer wg.Done()
+		// После остановки дочитываем upstream, чтобы не оставить заблокированным
+		// того, кто пишет в current
+		defer drain(current)
+		defer close(out)
+		for {
+			// Остановка приоритетнее чтения следующего элемента
+			if isClosed(stop) {
+				return
+			}
+
+			select {
+			case <-stop:
+				return
+			case v, ok := <-current:
+				if !ok {
+					return
+				}
+
+				res, err := stage.Fn(v)
+				if err != nil {
+					select {
+					case <-stop:
+						return
+					case deadLetters <- DeadLetter{Stage: stage.Name, Value: v, Err: err}:
+					}
+					onError()
+					continue
+				}
+
+				if isClosed(stop) {
+					return
+				}
+				select {
+				case <-stop:
+					return
+				case out <- res:
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,99 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errOdd = errors.New("odd value")
+
+func TestExecuteErrPipeline(t *testing.T) {
+	collect := func(out Out, deadLetters <-chan DeadLetter) ([]interface{}, []DeadLetter) {
+		var result []interface{}
+		var failed []DeadLetter
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for v := range out {
+				result = append(result, v)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for dl := range deadLetters {
+				failed = append(failed, dl)
+			}
+		}()
+		wg.Wait()
+		return result, failed
+	}
+
+	stages := []ErrStage{
+		{Name: "Even filter", Fn: func(v interface{}) (interface{}, error) {
+			if v.(int)%2 != 0 {
+				return nil, errOdd
+			}
+			return v, nil
+		}},
+		{Name: "Stringifier", Fn: func(v interface{}) (interface{}, error) {
+			return strconv.Itoa(v.(int)), nil
+		}},
+	}
+
+	t.Run("failed items go to dead letters", func(t *testing.T) {
+		out, deadLetters := ExecuteErrPipeline(produce([]int{1, 2, 3, 4, 5}), nil, 0, stages...)
+		result, failed := collect(out, deadLetters)
+
+		require.Equal(t, []interface{}{"2", "4"}, result)
+		require.Len(t, failed, 3)
+		for i, dl := range failed {
+			require.Equal(t, "Even filter", dl.Stage)
+			require.Equal(t, 2*i+1, dl.Value)
+			require.ErrorIs(t, dl, errOdd)
+		}
+	})
+
+	t.Run("abort after max errors", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		out, deadLetters := ExecuteErrPipeline(produce([]int{2, 1, 3, 4, 6, 8}), nil, 2, stages...)
+		result, failed := collect(out, deadLetters)
+
+		require.Len(t, failed, 2)
+		require.NotContains(t, result, "4")
+		require.NotContains(t, result, "8")
+		requireNoGoroutineLeaks(t, before)
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		done := make(Bi)
+		slow := ErrStage{Name: "Sleeper", Fn: func(v interface{}) (interface{}, error) {
+			time.Sleep(sleepPerStage)
+			return v, nil
+		}}
+
+		abortDur := sleepPerStage / 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		start := time.Now()
+		out, deadLetters := ExecuteErrPipeline(produce([]int{1, 2, 3}), done, 0, slow, slow)
+		result, failed := collect(out, deadLetters)
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Len(t, failed, 0)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)+int64(fault))
+		requireNoGoroutineLeaks(t, before)
+	})
+}
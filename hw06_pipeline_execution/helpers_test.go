@@ -0,0 +1,51 @@
+package hw06pipelineexecution
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireNoGoroutineLeaks проверяет, что число горутин вернулось к исходному.
+func requireNoGoroutineLeaks(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutines leaked")
+}
+
+// sleepStage возвращает стадию, которая применяет f к каждому значению после паузы sleep.
+func sleepStage(sleep time.Duration, f func(v interface{}) interface{}) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				time.Sleep(sleep)
+				out <- f(v)
+			}
+		}()
+		return out
+	}
+}
+
+// pass возвращает значение без изменений.
+func pass(v interface{}) interface{} {
+	return v
+}
+
+// produce отправляет data в новый канал и закрывает его.
+func produce(data []int) In {
+	in := make(Bi)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			in <- v
+		}
+	}()
+	return in
+}
@@ -0,0 +1,180 @@
+package hw06pipelineexecution
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StageStats — снимок метрик одной стадии.
+type StageStats struct {
+	Name     string
+	ItemsIn  int64 // Сколько элементов стадия приняла
+	ItemsOut int64 // Сколько элементов стадия отдала дальше
+	InFlight int64 // Сколько элементов сейчас внутри стадии
+
+	// AvgLatency — среднее время от приёма элемента до выдачи результата.
+	// Считается в порядке FIFO только для стадий, добавленных через AddOneToOne.
+	AvgLatency time.Duration
+	MaxLatency time.Duration
+
+	InputWait  time.Duration // Сколько upstream ждал, пока стадия примет элемент
+	OutputWait time.Duration // Сколько стадия ждала, пока downstream заберёт результат
+}
+
+// stageMetrics накапливает метрики стадии. Методы безопасны для nil-получателя,
+// так что неинструментированные стадии ничего не считают.
+type stageMetrics struct {
+	mu         sync.Mutex
+	name       string
+	oneToOne   bool // Стадия выдаёт ровно один результат на вход: можно считать latency
+	itemsIn    int64
+	itemsOut   int64
+	accepted   []time.Time // Время приёма элементов, ещё не вышедших из стадии
+	early      int64       // Результаты, выданные раньше, чем учтён приём входа
+	measured   int64       // Сколько задержек учтено в latency
+	latency    time.Duration
+	maxLatency time.Duration
+	inputWait  time.Duration
+	outputWait time.Duration
+}
+
+func (m *stageMetrics) now() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (m *stageMetrics) itemIn(start time.Time) {
+	if m == nil {
+		return
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.itemsIn++
+	m.inputWait += now.Sub(start)
+	if !m.oneToOne {
+		// Без соответствия входов и выходов очередь росла бы бесконечно
+		return
+	}
+	if m.early > 0 {
+		// Стадия успела выдать результат до того, как мы учли приём
+		m.early--
+		return
+	}
+	m.accepted = append(m.accepted, now)
+}
+
+func (m *stageMetrics) itemOut(start time.Time) {
+	if m == nil {
+		return
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.itemsOut++
+	m.outputWait += now.Sub(start)
+	if !m.oneToOne {
+		return
+	}
+	if len(m.accepted) == 0 {
+		m.early++
+		return
+	}
+	latency := start.Sub(m.accepted[0])
+	m.accepted = m.accepted[1:]
+	m.measured++
+	m.latency += latency
+	if latency > m.maxLatency {
+		m.maxLatency = latency
+	}
+}
+
+func (m *stageMetrics) snapshot() StageStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := StageStats{
+		Name:       m.name,
+		ItemsIn:    m.itemsIn,
+		ItemsOut:   m.itemsOut,
+		MaxLatency: m.maxLatency,
+		InputWait:  m.inputWait,
+		OutputWait: m.outputWait,
+	}
+	if m.itemsIn > m.itemsOut {
+		stats.InFlight = m.itemsIn - m.itemsOut
+	}
+	if m.measured > 0 {
+		stats.AvgLatency = m.latency / time.Duration(m.measured)
+	}
+	return stats
+}
+
+// Pipeline — конвейер из именованных стадий со сбором метрик.
+type Pipeline struct {
+	done    In
+	stages  []Stage
+	metrics []*stageMetrics
+}
+
+// NewPipeline создаёт пустой конвейер, останавливаемый закрытием done.
+func NewPipeline(done In) *Pipeline {
+	return &Pipeline{done: done}
+}
+
+// Add добавляет стадию в конец конвейера. AvgLatency и MaxLatency для неё
+// остаются нулевыми: задержка измеряется только у стадий из AddOneToOne,
+// потому что Filter, Batch и подобные выдают не по результату на вход.
+func (p *Pipeline) Add(name string, stage Stage) *Pipeline {
+	return p.add(name, stage, false)
+}
+
+// AddOneToOne добавляет стадию, выдающую ровно один результат на каждый
+// вход в том же порядке, и считает по ней AvgLatency и MaxLatency.
+func (p *Pipeline) AddOneToOne(name string, stage Stage) *Pipeline {
+	return p.add(name, stage, true)
+}
+
+func (p *Pipeline) add(name string, stage Stage, oneToOne bool) *Pipeline {
+	p.stages = append(p.stages, stage)
+	p.metrics = append(p.metrics, &stageMetrics{name: name, oneToOne: oneToOne})
+	return p
+}
+
+// Run запускает конвейер на входном канале in.
+func (p *Pipeline) Run(in In) Out {
+	current := in
+
+	for i, stage := range p.stages {
+		current = layerStage(stage, current, p.done, p.metrics[i])
+	}
+
+	return current
+}
+
+// Stats возвращает снимок метрик всех стадий в порядке их следования.
+func (p *Pipeline) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(p.metrics))
+	for _, m := range p.metrics {
+		stats = append(stats, m.snapshot())
+	}
+	return stats
+}
+
+// String выводит граф конвейера с текущими значениями счётчиков.
+func (p *Pipeline) String() string {
+	var sb strings.Builder
+	sb.WriteString("in\n")
+	for _, s := range p.Stats() {
+		fmt.Fprintf(&sb, "  -> [%s] in=%d out=%d inflight=%d avg=%v max=%v in_wait=%v out_wait=%v\n",
+			s.Name, s.ItemsIn, s.ItemsOut, s.InFlight, s.AvgLatency, s.MaxLatency, s.InputWait, s.OutputWait)
+	}
+	sb.WriteString("  -> out\n")
+	return sb.String()
+}
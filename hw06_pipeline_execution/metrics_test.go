@@ -0,0 +1,94 @@
+package hw06pipelineexecution
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineStats(t *testing.T) {
+	t.Run("counters and latency", func(t *testing.T) {
+		p := NewPipeline(nil).
+			AddOneToOne("Fast", sleepStage(0, pass)).
+			AddOneToOne("Slow", sleepStage(sleepPerStage/2, pass))
+
+		count := 0
+		for range p.Run(produce([]int{1, 2, 3, 4})) {
+			count++
+		}
+		require.Equal(t, 4, count)
+
+		stats := p.Stats()
+		require.Len(t, stats, 2)
+		for _, s := range stats {
+			require.Equal(t, int64(4), s.ItemsIn)
+			require.Equal(t, int64(4), s.ItemsOut)
+			require.Equal(t, int64(0), s.InFlight)
+		}
+		require.Equal(t, "Fast", stats[0].Name)
+		require.Equal(t, "Slow", stats[1].Name)
+
+		require.GreaterOrEqual(t, int64(stats[1].AvgLatency), int64(sleepPerStage/2))
+		require.Less(t, int64(stats[0].AvgLatency), int64(stats[1].AvgLatency))
+		// Быстрая стадия упирается в медленную
+		require.Greater(t, int64(stats[0].OutputWait), int64(stats[1].OutputWait))
+	})
+
+	t.Run("live dump", func(t *testing.T) {
+		p := NewPipeline(nil).Add("Dummy", sleepStage(sleepPerStage, pass))
+		out := p.Run(produce([]int{1, 2}))
+
+		// Счётчики обновляются асинхронно относительно читателя
+		<-out
+		require.Eventually(t, func() bool {
+			return strings.Contains(p.String(), "[Dummy] in=2 out=1 inflight=1")
+		}, sleepPerStage/2, time.Millisecond)
+
+		<-out
+		require.Eventually(t, func() bool {
+			return strings.Contains(p.String(), "[Dummy] in=2 out=2 inflight=0")
+		}, sleepPerStage/2, time.Millisecond)
+	})
+
+	t.Run("filter keeps latency queue empty", func(t *testing.T) {
+		data := make([]int, 1000)
+		for i := range data {
+			data[i] = i
+		}
+		p := NewPipeline(nil).
+			Add("Filter", Filter(func(v interface{}) bool { return v.(int)%100 == 0 }, nil)).
+			AddOneToOne("Dummy", sleepStage(0, pass))
+
+		count := 0
+		for range p.Run(produce(data)) {
+			count++
+		}
+		require.Equal(t, 10, count)
+
+		stats := p.Stats()
+		require.Equal(t, int64(1000), stats[0].ItemsIn)
+		require.Equal(t, int64(10), stats[0].ItemsOut)
+		require.Zero(t, stats[0].AvgLatency)
+		require.Empty(t, p.metrics[0].accepted)
+		require.Empty(t, p.metrics[1].accepted)
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		go func() {
+			<-time.After(sleepPerStage / 2)
+			close(done)
+		}()
+
+		p := NewPipeline(done).Add("Slow", sleepStage(sleepPerStage, pass))
+		count := 0
+		for range p.Run(produce([]int{1, 2, 3})) {
+			count++
+		}
+
+		require.Equal(t, 0, count)
+		require.Equal(t, int64(0), p.Stats()[0].ItemsOut)
+	})
+}
@@ -0,0 +1,125 @@
+package hw06pipelineexecution
+
+import "sync"
+
+// seqItem связывает значение с его порядковым номером во входном потоке.
+type seqItem struct {
+	seq uint64
+	v   interface{}
+}
+
+// ParallelStage возвращает Stage, который раздаёт элементы workers воркерам
+// и собирает результаты обратно в один канал. При ordered=true результаты
+// выдаются в порядке поступления входных элементов (через буфер переупорядочивания).
+// Как и LayerStage, стадия прекращает работу при закрытии done.
+func ParallelStage(stage func(v interface{}) interface{}, workers int, ordered bool, done In) Stage {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return func(in In) Out {
+		jobs := make(chan seqItem)
+		results := make(chan seqItem)
+		out := make(Bi)
+
+		go func() {
+			defer drain(in)
+			defer close(jobs)
+			var seq uint64
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case <-done:
+						return
+					case jobs <- seqItem{seq: seq, v: v}:
+						seq++
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		wg := sync.WaitGroup{}
+		wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer wg.Done()
+				for item := range jobs {
+					item.v = stage(item.v)
+					select {
+					case <-done:
+						return
+					case results <- item:
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		go func() {
+			defer close(out)
+			if ordered {
+				reorder(results, out, done)
+				return
+			}
+			for item := range results {
+				if isClosed(done) {
+					return
+				}
+				select {
+				case <-done:
+					return
+				case out <- item.v:
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// reorder выдаёт элементы в out строго по возрастанию seq,
+// придерживая пришедшие раньше времени в буфере.
+func reorder(results <-chan seqItem, out Bi, done In) {
+	pending := make(map[uint64]interface{})
+	var next uint64
+
+	for item := range results {
+		pending[item.seq] = item.v
+		for {
+			v, ok := pending[next]
+			if !ok {
+				break
+			}
+			if isClosed(done) {
+				return
+			}
+			select {
+			case <-done:
+				return
+			case out <- v:
+			}
+			delete(pending, next)
+			next++
+		}
+	}
+}
+
+// isClosed сообщает, закрыт ли канал, не блокируясь.
+func isClosed[T any](ch <-chan T) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package hw06pipelineexecution
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelStage(t *testing.T) {
+	const workers = 5
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	produce := func() In {
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for _, v := range data {
+				in <- v
+			}
+		}()
+		return in
+	}
+
+	slowDouble := func(v interface{}) interface{} {
+		time.Sleep(sleepPerStage)
+		return v.(int) * 2
+	}
+
+	t.Run("ordered case", func(t *testing.T) {
+		jitter := func(v interface{}) interface{} {
+			time.Sleep(time.Millisecond * time.Duration(rand.Intn(50)))
+			return v.(int) * 2
+		}
+
+		result := make([]int, 0, len(data))
+		for v := range ExecutePipeline(produce(), nil, ParallelStage(jitter, workers, true, nil)) {
+			result = append(result, v.(int))
+		}
+
+		require.Equal(t, []int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20}, result)
+	})
+
+	t.Run("unordered case", func(t *testing.T) {
+		result := make([]int, 0, len(data))
+		start := time.Now()
+		for v := range ExecutePipeline(produce(), nil, ParallelStage(slowDouble, workers, false, nil)) {
+			result = append(result, v.(int))
+		}
+		elapsed := time.Since(start)
+
+		sort.Ints(result)
+		require.Equal(t, []int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20}, result)
+		// 10 значений по 100ms на 5 воркерах ~0.2s
+		require.Less(t, int64(elapsed), int64(sleepPerStage)*int64(len(data)/workers)+int64(fault))
+	})
+
+	t.Run("mixed with sequential stages", func(t *testing.T) {
+		stringify := func(in In) Out {
+			out := make(Bi)
+			go func() {
+				defer close(out)
+				for v := range in {
+					out <- strconv.Itoa(v.(int))
+				}
+			}()
+			return out
+		}
+
+		result := make([]string, 0, len(data))
+		for v := range ExecutePipeline(produce(), nil, ParallelStage(slowDouble, workers, true, nil), stringify) {
+			result = append(result, v.(string))
+		}
+
+		require.Equal(t, []string{"2", "4", "6", "8", "10", "12", "14", "16", "18", "20"}, result)
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		abortDur := sleepPerStage / 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		result := make([]int, 0, len(data))
+		start := time.Now()
+		for v := range ExecutePipeline(produce(), done, ParallelStage(slowDouble, workers, true, done)) {
+			result = append(result, v.(int))
+		}
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)+int64(fault))
+	})
+}
@@ -1,5 +1,7 @@
 package hw06pipelineexecution
 
+import "context"
+
 type (
 	In  = <-chan interface{}
 	Out = In
@@ -8,6 +10,9 @@ type (
 
 type Stage func(in In) (out Out)
 
+// ExecutePipeline прогоняет значения из in через стадии по порядку.
+// Вызывающий обязан закрыть in: после отмены через done первая стадия
+// дочитывает in до закрытия, и без этого её горутина не завершится.
 func ExecutePipeline(in In, done In, stages ...Stage) Out {
 	current := in
 
@@ -18,11 +23,32 @@ func ExecutePipeline(in In, done In, stages ...Stage) Out {
 	return current
 }
 
+// ExecutePipelineContext работает как ExecutePipeline, но отменяется через ctx.
+// После отмены все горутины стадий завершаются: входные каналы вычитываются до закрытия.
+// Поэтому in, как и в ExecutePipeline, должен быть закрыт вызывающим.
+func ExecutePipelineContext(ctx context.Context, in In, stages ...Stage) Out {
+	current := in
+
+	for _, stage := range stages {
+		current = layerStage(stage, current, ctx.Done(), nil)
+	}
+
+	return current
+}
+
 func LayerStage(stage Stage, current In, done In) Out {
+	return layerStage(stage, current, done, nil)
+}
+
+// layerStage оборачивает стадию; если m != nil, собирает по ней метрики.
+func layerStage[T any](stage Stage, current In, done <-chan T, m *stageMetrics) Out {
 	out := make(Bi)
 	layerCurrent := make(Bi)
 
 	go func() {
+		// Сначала закрываем вход стадии, затем дочитываем upstream,
+		// чтобы не оставить заблокированной предыдущую стадию
+		defer drain(current)
 		defer close(layerCurrent)
 		for {
 			select {
@@ -30,10 +56,12 @@ func LayerStage(stage Stage, current In, done In) Out {
 				if !ok {
 					return
 				}
+				start := m.now()
 				select {
 				case <-done:
 					return
 				case layerCurrent <- v:
+					m.itemIn(start)
 				}
 			case <-done:
 				return
@@ -44,15 +72,28 @@ func LayerStage(stage Stage, current In, done In) Out {
 	stageOut := stage(layerCurrent)
 
 	go func() {
+		// Вычитываем stageOut после отмены, иначе стадия зависнет на отправке
+		defer drain(stageOut)
 		defer close(out)
 		for v := range stageOut {
+			if isClosed(done) {
+				return
+			}
+			start := m.now()
 			select {
 			case <-done:
 				return
 			case out <- v:
+				m.itemOut(start)
 			}
 		}
 	}()
 
 	return out
 }
+
+// drain вычитывает канал до его закрытия.
+func drain(ch In) {
+	for range ch {
+	}
+}
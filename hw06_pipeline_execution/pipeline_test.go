@@ -1,6 +1,8 @@
 package hw06pipelineexecution
 
 import (
+	"context"
+	"runtime"
 	"strconv"
 	"sync"
 	"testing"
@@ -146,3 +148,73 @@ func TestAllStageStop(t *testing.T) {
 		require.Len(t, result, 0)
 	})
 }
+
+func TestExecutePipelineContext(t *testing.T) {
+	stages := []Stage{
+		sleepStage(sleepPerStage, pass),
+		sleepStage(sleepPerStage, func(v interface{}) interface{} { return v.(int) * 2 }),
+		sleepStage(sleepPerStage, func(v interface{}) interface{} { return v.(int) + 100 }),
+		sleepStage(sleepPerStage, func(v interface{}) interface{} { return strconv.Itoa(v.(int)) }),
+	}
+
+	t.Run("simple case", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		result := make([]string, 0, 10)
+		for s := range ExecutePipelineContext(context.Background(), produce([]int{1, 2, 3, 4, 5}), stages...) {
+			result = append(result, s.(string))
+		}
+
+		require.Equal(t, []string{"102", "104", "106", "108", "110"}, result)
+		requireNoGoroutineLeaks(t, before)
+	})
+
+	t.Run("cancel case", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		abortDur := sleepPerStage * 2
+		ctx, cancel := context.WithTimeout(context.Background(), abortDur)
+		defer cancel()
+
+		result := make([]string, 0, 10)
+		start := time.Now()
+		for s := range ExecutePipelineContext(ctx, produce([]int{1, 2, 3, 4, 5}), stages...) {
+			result = append(result, s.(string))
+		}
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+		requireNoGoroutineLeaks(t, before)
+	})
+
+	t.Run("cancel with reader gone", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		out := ExecutePipelineContext(ctx, produce([]int{1, 2, 3, 4, 5}), stages...)
+		<-out
+		cancel()
+
+		requireNoGoroutineLeaks(t, before)
+	})
+
+	t.Run("cancel with open input", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		// in не закрыт: после отмены выход закрывается, но первая стадия
+		// ждёт закрытия in, чтобы не оставить источник заблокированным
+		in := make(Bi)
+		ctx, cancel := context.WithCancel(context.Background())
+		out := ExecutePipelineContext(ctx, in, stages...)
+		in <- 1
+		cancel()
+
+		for range out {
+		}
+		require.Greater(t, runtime.NumGoroutine(), before)
+
+		close(in)
+		requireNoGoroutineLeaks(t, before)
+	})
+}
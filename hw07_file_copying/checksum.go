@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+var (
+	ErrUnknownHash      = errors.New("unknown hash algorithm")
+	ErrChecksumMismatch = errors.New("destination checksum mismatch")
+)
+
+// HashAlgo — алгоритм контрольной суммы скопированного диапазона.
+type HashAlgo string
+
+const (
+	HashSHA256 HashAlgo = "sha256"
+	HashCRC32C HashAlgo = "crc32c"
+	HashXXHash HashAlgo = "xxhash"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func newHash(algo HashAlgo) (hash.Hash, error) {
+	switch algo {
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashCRC32C:
+		return crc32.New(crc32cTable), nil
+	case HashXXHash:
+		return xxhash.New(), nil
+	default:
+		return nil, fmt.Errorf("%q: %w", algo, ErrUnknownHash)
+	}
+}
+
+// digester считает несколько контрольных сумм за один проход по данным.
+type digester struct {
+	algos  []HashAlgo
+	hashes []hash.Hash
+	w      io.Writer
+}
+
+func newDigester(algos []HashAlgo) (*digester, error) {
+	d := &digester{algos: algos}
+	writers := make([]io.Writer, 0, len(algos))
+	for _, algo := range algos {
+		h, err := newHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		d.hashes = append(d.hashes, h)
+		writers = append(writers, h)
+	}
+	d.w = io.MultiWriter(writers...)
+	return d, nil
+}
+
+func (d *digester) Write(p []byte) (int, error) {
+	return d.w.Write(p)
+}
+
+// Sums возвращает контрольные суммы в шестнадцатеричном виде.
+func (d *digester) Sums() map[HashAlgo]string {
+	sums := make(map[HashAlgo]string, len(d.algos))
+	for i, algo := range d.algos {
+		sums[algo] = hex.EncodeToString(d.hashes[i].Sum(nil))
+	}
+	return sums
+}
+
+// verify перечитывает первые n байт r и сравнивает их суммы с want.
+func verify(r io.ReaderAt, n int64, want map[HashAlgo]string) error {
+	algos := make([]HashAlgo, 0, len(want))
+	for algo := range want {
+		algos = append(algos, algo)
+	}
+
+	d, err := newDigester(algos)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(d, io.NewSectionReader(r, 0, n)); err != nil {
+		return fmt.Errorf("read destination: %w", err)
+	}
+
+	for algo, sum := range d.Sums() {
+		if sum != want[algo] {
+			return fmt.Errorf("%s %s, expected %s: %w", algo, sum, want[algo], ErrChecksumMismatch)
+		}
+	}
+	return nil
+}
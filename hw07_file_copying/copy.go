@@ -1,72 +1,492 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-
-	"github.com/cheggaaa/pb"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	ErrUnsupportedFile       = errors.New("unsupported file")
 	ErrOffsetExceedsFileSize = errors.New("offset exceeds file size")
+	ErrNegativeArgument      = errors.New("offset and limit must not be negative")
+	ErrIncompatibleOptions   = errors.New("atomic and resume modes can't be used together")
+	ErrNotSeekable           = errors.New("file is not seekable")
 )
 
+// Options задаёт режим копирования.
+type Options struct {
+	// Atomic — писать во временный файл рядом с назначением, делать fsync и переименовывать.
+	// Прерванное копирование не портит существующий файл назначения.
+	Atomic bool
+	// Resume — если файл назначения уже частично скопирован и его содержимое
+	// совпадает с началом копируемого диапазона, дописать только недостающее.
+	Resume bool
+	// Hashes — контрольные суммы копируемого диапазона, которые считаются на лету.
+	Hashes []HashAlgo
+	// Verify — после копирования перечитать файл назначения и сверить контрольные суммы.
+	// Если Hashes не заданы, для проверки используется SHA-256.
+	Verify bool
+	// BandwidthLimit — ограничение скорости записи, байт/с. 0 — без ограничения.
+	BandwidthLimit int64
+	// Progress получает ход копирования. По умолчанию выводится прогресс-бар.
+	Progress ProgressReporter
+}
+
+// CopyResult — итог копирования.
+type CopyResult struct {
+	Bytes      int64               // Сколько байт скопировано за этот запуск
+	Resumed    int64               // Сколько байт уже было в файле назначения (режим Resume)
+	Duration   time.Duration       // Время копирования
+	Throughput float64             // Скорость, байт/с
+	Digests    map[HashAlgo]string // Контрольные суммы всего скопированного диапазона
+}
+
+// stdio — путь, обозначающий stdin для -from и stdout для -to.
+const stdio = "-"
+
 func Copy(fromPath, toPath string, offset, limit int64) error {
-	src, err := os.Open(fromPath)
+	_, err := CopyWithOptions(fromPath, toPath, offset, limit, Options{})
+	return err
+}
+
+// CopyWithOptions копирует данные из fromPath в toPath. Источником может быть
+// не только обычный файл, но и поток (stdin, pipe, устройство) неизвестной длины:
+// тогда offset байт пропускаются чтением, а копирование идёт до limit или EOF.
+func CopyWithOptions(fromPath, toPath string, offset, limit int64, opts Options) (CopyResult, error) {
+	var result CopyResult
+	start := time.Now()
+
+	if offset < 0 || limit < 0 {
+		return result, ErrNegativeArgument
+	}
+	if opts.Atomic && opts.Resume {
+		return result, ErrIncompatibleOptions
+	}
+	if opts.BandwidthLimit < 0 {
+		return result, fmt.Errorf("%d: %w", opts.BandwidthLimit, ErrInvalidBandwidth)
+	}
+	if toPath == stdio && (opts.Atomic || opts.Resume || opts.Verify) {
+		return result, fmt.Errorf("stdout with atomic, resume or verify mode: %w", ErrNotSeekable)
+	}
+
+	algos := opts.Hashes
+	if opts.Verify && len(algos) == 0 {
+		algos = []HashAlgo{HashSHA256}
+	}
+	digests, err := newDigester(algos)
 	if err != nil {
-		fmt.Println("open err:", err)
-		return err
+		return result, err
 	}
-	defer src.Close()
+
+	src, err := openSource(fromPath)
+	if err != nil {
+		return result, err
+	}
+	defer closeSource(src)
 
 	// Получаем информацию о файле
 	info, err := src.Stat()
 	if err != nil {
-		fmt.Println("info err:", err)
-		return err
+		return result, fmt.Errorf("stat source: %w", err)
 	}
 
-	// Узнаем размер всего файла из которого копируем
-	size := info.Size()
-	if size < offset {
-		fmt.Printf("file size %x is less than offset %x\n", size, offset)
-		return err
+	// Каталог прочитать как поток байт нельзя
+	if info.IsDir() {
+		return result, fmt.Errorf("%s: %w", fromPath, ErrUnsupportedFile)
 	}
 
-	// Перемещаемся на offset
-	_, err = src.Seek(offset, io.SeekStart)
+	// У потоков (pipe, устройство) размер неизвестен и перемещаться по ним нельзя
+	regular := info.Mode().IsRegular()
+	if !regular && opts.Resume {
+		return result, fmt.Errorf("resume from %s: %w", fromPath, ErrNotSeekable)
+	}
+
+	copySize := int64(-1)
+	if !regular {
+		// Пропускаем offset до создания файла назначения, чтобы не оставить пустой файл при ошибке
+		if err = skipStream(src, offset); err != nil {
+			return result, err
+		}
+	} else {
+		// Узнаем размер всего файла из которого копируем
+		size := info.Size()
+		if offset > size {
+			return result, fmt.Errorf("offset %d, file size %d: %w", offset, size, ErrOffsetExceedsFileSize)
+		}
+
+		// Определяем размер копирования
+		remaining := size - offset
+		copySize = remaining
+		if limit > 0 && limit < remaining {
+			copySize = limit
+		}
+	}
+
+	dst, err := openDestination(src, toPath, offset, copySize, opts)
 	if err != nil {
-		fmt.Println("you can't move to offset:", err)
-		return err
+		return result, err
 	}
+	defer dst.discard()
 
-	// Оапеделяем размер копирования
-	remaining := size - offset
-	copySize := remaining
-	if limit > 0 && limit < remaining {
-		copySize = limit
+	// Уже скопированная часть не читается из источника, но входит в контрольные суммы
+	if dst.done > 0 {
+		if _, err = io.Copy(digests, io.NewSectionReader(dst, 0, dst.done)); err != nil {
+			return result, fmt.Errorf("read destination: %w", err)
+		}
 	}
 
-	// Создаем файл для копирования
-	dst, err := os.Create(toPath)
+	// Без контрольных сумм данные не нужны в userspace, и в обычный файл
+	// можно копировать средствами ядра
+	var w io.Writer = dst
+	switch {
+	case len(algos) > 0:
+		w = io.MultiWriter(dst, digests)
+	case !dst.stdout && dst.regular():
+		w = dst.File
+	}
+	// Ограничение скорости тоже требует копирования через userspace
+	if opts.BandwidthLimit > 0 {
+		w = &limitedWriter{w: w, bucket: newTokenBucket(opts.BandwidthLimit)}
+	}
+
+	progress := opts.Progress
+	if progress == nil {
+		progress = defaultProgress(dst.stdout)
+	}
+
+	var copied int64
+	if regular {
+		err = copyRange(w, src, offset, copySize, dst.done, progress)
+		copied = copySize - dst.done
+	} else {
+		copied, err = copyFromStream(w, src, limit, progress)
+	}
 	if err != nil {
-		fmt.Println("info err:", err)
-		return err
+		return result, err
 	}
-	defer dst.Close()
 
-	// Для прогресс-бара используем copySize - это реальное количество байт, которые будем копировать
-	bar := pb.StartNew(int(copySize))
+	result.Digests = digests.Sums()
+	if opts.Verify {
+		if err = verify(dst, dst.done+copied, result.Digests); err != nil {
+			return result, err
+		}
+	}
 
-	reader := bar.NewProxyReader(src)
+	if err = dst.commit(); err != nil {
+		return result, err
+	}
+
+	result.Resumed = dst.done
+	result.Bytes = copied
+	result.Duration = time.Since(start)
+	if result.Duration > 0 {
+		result.Throughput = float64(result.Bytes) / result.Duration.Seconds()
+	}
+	return result, nil
+}
+
+func openSource(fromPath string) (*os.File, error) {
+	if fromPath == stdio {
+		return os.Stdin, nil
+	}
+	src, err := os.Open(fromPath)
+	if err != nil {
+		return nil, fmt.Errorf("open source: %w", err)
+	}
+	return src, nil
+}
+
+func closeSource(src *os.File) {
+	if src != os.Stdin {
+		src.Close()
+	}
+}
+
+// defaultProgress создаёт прогресс-бар. Когда данные пишутся в stdout,
+// прогресс выводится в stderr.
+func defaultProgress(toStdout bool) ProgressReporter {
+	if toStdout {
+		return NewBarProgress(os.Stderr)
+	}
+	return NewBarProgress(os.Stdout)
+}
+
+// skipStream пропускает offset байт потока, по которому нельзя перемещаться.
+func skipStream(src io.Reader, offset int64) error {
+	skipped, err := io.CopyN(io.Discard, src, offset)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("offset %d, stream size %d: %w", offset, skipped, ErrOffsetExceedsFileSize)
+	}
+	if err != nil {
+		return fmt.Errorf("skip offset: %w", err)
+	}
+	return nil
+}
+
+// copyFromStream копирует limit байт потока (или всё до EOF, если limit = 0).
+// Возвращает число скопированных байт.
+func copyFromStream(dst io.Writer, src io.Reader, limit int64, progress ProgressReporter) (int64, error) {
+	progress.Start(limit)
+	reader := &progressReader{r: src, progress: progress}
+
+	var copied int64
+	var err error
+	if limit > 0 {
+		copied, err = io.CopyN(dst, reader, limit)
+		// Поток может закончиться раньше limit — это не ошибка, как и для файлов
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	} else {
+		copied, err = io.Copy(dst, reader)
+	}
+
+	progress.Finish()
+	if err != nil {
+		return copied, fmt.Errorf("copy: %w", err)
+	}
+	return copied, nil
+}
+
+// progressPollInterval — как часто обновляется прогресс при копировании средствами ядра.
+const progressPollInterval = 100 * time.Millisecond
+
+// copyRange копирует copySize байт источника, начиная с offset, пропуская
+// первые done байт, которые уже есть в dst. Ход копирования сообщается в progress.
+// Если dst — файл, используется быстрый путь без копирования через userspace.
+func copyRange(dst io.Writer, src *os.File, offset, copySize, done int64, progress ProgressReporter) error {
+	// Перемещаемся на offset
+	if _, err := src.Seek(offset+done, io.SeekStart); err != nil {
+		return fmt.Errorf("seek source: %w", err)
+	}
+
+	// Для прогресса используем copySize - это реальное количество байт, которые будем копировать
+	progress.Start(copySize)
+	progress.Set(done)
+
+	var err error
+	if f, ok := dst.(*os.File); ok && fastCopySupported {
+		err = copyFastWithProgress(f, src, copySize-done, progress)
+	} else {
+		err = copyStream(dst, src, copySize-done, progress)
+	}
+
+	progress.Finish()
+	return err
+}
+
+// copyStream копирует n байт через буфер, считая прогресс по прочитанным данным.
+func copyStream(dst io.Writer, src io.Reader, n int64, progress ProgressReporter) error {
+	reader := &progressReader{r: src, progress: progress}
 
 	// Использовать обернутый reader для копирования
-	io.CopyN(dst, reader, copySize)
+	if _, err := io.CopyN(dst, reader, n); err != nil {
+		return fmt.Errorf("copy: %w", err)
+	}
+	return nil
+}
+
+// copyFastWithProgress копирует n байт через copyFast. Ядро не сообщает о ходе
+// копирования, поэтому прогресс периодически опрашивается из счётчика.
+func copyFastWithProgress(dst, src *os.File, n int64, progress ProgressReporter) error {
+	var copied, reported atomic.Int64
+	report := func() {
+		c := copied.Load()
+		progress.Add(c - reported.Swap(c))
+	}
+
+	stop := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		ticker := time.NewTicker(progressPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				report()
+			}
+		}
+	}()
+
+	err := copyFast(dst, src, n, &copied)
+	close(stop)
+	<-polled
+
+	report()
+	return err
+}
+
+// destination — открытый файл назначения с учётом режима копирования.
+type destination struct {
+	*os.File
+	path      string
+	done      int64 // Сколько байт уже скопировано ранее (режим Resume)
+	tmp       bool  // Файл временный и при commit переименовывается в path
+	stdout    bool  // Запись идёт в stdout, закрывать его не нужно
+	committed bool
+}
+
+// regular сообщает, обычный ли это файл. copyFast пишет по смещениям и
+// пропускает дыры источника, поэтому в устройство или FIFO пишем через буфер.
+func (d *destination) regular() bool {
+	info, err := d.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
+
+func openDestination(src *os.File, toPath string, offset, copySize int64, opts Options) (*destination, error) {
+	switch {
+	case toPath == stdio:
+		return &destination{File: os.Stdout, path: toPath, stdout: true}, nil
+	case opts.Atomic:
+		return createTemp(toPath)
+	case opts.Resume:
+		return openResume(src, toPath, offset, copySize)
+	default:
+		// Создаем файл для копирования
+		f, err := os.Create(toPath)
+		if err != nil {
+			return nil, fmt.Errorf("create destination: %w", err)
+		}
+		return &destination{File: f, path: toPath}, nil
+	}
+}
+
+// createTemp создаёт временный файл в каталоге назначения.
+func createTemp(toPath string) (*destination, error) {
+	f, err := os.CreateTemp(filepath.Dir(toPath), "."+filepath.Base(toPath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	d := &destination{File: f, path: toPath, tmp: true}
+
+	// CreateTemp создаёт файл с правами 0600; сохраняем права существующего файла
+	perm := os.FileMode(0o644)
+	if info, statErr := os.Stat(toPath); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+	if err = f.Chmod(perm); err != nil {
+		d.discard()
+		return nil, fmt.Errorf("chmod temp file: %w", err)
+	}
+	return d, nil
+}
+
+// openResume открывает файл назначения для дописывания, если уже скопированная
+// часть совпадает с источником, иначе очищает его.
+func openResume(src *os.File, toPath string, offset, copySize int64) (*destination, error) {
+	f, err := os.OpenFile(toPath, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, fmt.Errorf("open destination: %w", err)
+	}
+	d := &destination{File: f, path: toPath}
 
-	// Завершить прогресс-бар
-	bar.Finish()
+	info, err := f.Stat()
+	if err != nil {
+		d.discard()
+		return nil, fmt.Errorf("stat destination: %w", err)
+	}
+
+	done := info.Size()
+	if done > 0 {
+		same, err := samePrefix(src, f, offset, done, copySize)
+		if err != nil {
+			d.discard()
+			return nil, err
+		}
+		if !same {
+			done = 0
+		}
+	}
+
+	if err = f.Truncate(done); err != nil {
+		d.discard()
+		return nil, fmt.Errorf("truncate destination: %w", err)
+	}
+	if _, err = f.Seek(done, io.SeekStart); err != nil {
+		d.discard()
+		return nil, fmt.Errorf("seek destination: %w", err)
+	}
+	d.done = done
+	return d, nil
+}
+
+// commit завершает запись. Временный файл синхронизируется на диск
+// и переименовывается в файл назначения.
+func (d *destination) commit() error {
+	if d.stdout {
+		d.committed = true
+		return nil
+	}
+	if !d.tmp {
+		d.committed = true
+		if err := d.Close(); err != nil {
+			return fmt.Errorf("close destination: %w", err)
+		}
+		return nil
+	}
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := d.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(d.Name(), d.path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	d.committed = true
+
+	// Сохраняем на диск и запись каталога о переименовании.
+	// Не все файловые системы это поддерживают, поэтому ошибку игнорируем.
+	if dir, err := os.Open(filepath.Dir(d.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
 	return nil
 }
+
+// discard закрывает файл, если commit не был выполнен, и удаляет временный файл.
+func (d *destination) discard() {
+	if d.committed || d.stdout {
+		return
+	}
+	d.Close()
+	if d.tmp {
+		os.Remove(d.Name())
+	}
+}
+
+// samePrefix сравнивает контрольные суммы первых n байт dst и диапазона источника.
+func samePrefix(src, dst *os.File, offset, n, copySize int64) (bool, error) {
+	if n > copySize {
+		return false, nil
+	}
+
+	srcSum, err := sha256Of(io.NewSectionReader(src, offset, n))
+	if err != nil {
+		return false, fmt.Errorf("checksum source: %w", err)
+	}
+	dstSum, err := sha256Of(io.NewSectionReader(dst, 0, n))
+	if err != nil {
+		return false, fmt.Errorf("checksum destination: %w", err)
+	}
+	return bytes.Equal(srcSum, dstSum), nil
+}
+
+func sha256Of(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
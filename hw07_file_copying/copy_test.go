@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -73,4 +78,292 @@ func TestCopy(t *testing.T) {
 		actual, _ := os.ReadFile(to)
 		require.Equal(t, expected, actual)
 	})
+
+	t.Run("to character device", func(t *testing.T) {
+		result, err := CopyWithOptions("testdata/input.txt", "/dev/null", 100, 1000, Options{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), result.Bytes)
+	})
+}
+
+func TestCopyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		offset int64
+		limit  int64
+		err    error
+	}{
+		{name: "offset exceeds file size", from: "testdata/input.txt", offset: 7000, err: ErrOffsetExceedsFileSize},
+		{name: "directory", from: "testdata", err: ErrUnsupportedFile},
+		{name: "missing source", from: "testdata/not_exists.txt", err: fs.ErrNotExist},
+		{name: "negative offset", from: "testdata/input.txt", offset: -1, err: ErrNegativeArgument},
+		{name: "negative limit", from: "testdata/input.txt", limit: -1, err: ErrNegativeArgument},
+		{name: "offset exceeds stream size", from: "/dev/null", offset: 1, err: ErrOffsetExceedsFileSize},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.txt")
+
+			err := Copy(tc.from, to, tc.offset, tc.limit)
+			require.Truef(t, errors.Is(err, tc.err), "actual err - %v", err)
+
+			// При ошибке валидации файл назначения не создаётся
+			_, err = os.Stat(to)
+			require.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+
+	t.Run("offset equals file size", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy("testdata/input.txt", to, 6617, 0)
+		require.NoError(t, err)
+
+		actual, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+}
+
+func TestCopyAtomic(t *testing.T) {
+	from := "testdata/input.txt"
+
+	t.Run("replaces destination", func(t *testing.T) {
+		dir := t.TempDir()
+		to := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(to, []byte("old content"), 0o600))
+
+		_, err := CopyWithOptions(from, to, 100, 1000, Options{Atomic: true})
+		require.NoError(t, err)
+
+		expected, _ := os.ReadFile("testdata/expected_offset100_limit1000.txt")
+		actual, _ := os.ReadFile(to)
+		require.Equal(t, expected, actual)
+
+		// Права существующего файла сохраняются, временных файлов не остаётся
+		info, err := os.Stat(to)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("incompatible with resume", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+
+		_, err := CopyWithOptions(from, to, 0, 0, Options{Atomic: true, Resume: true})
+		require.ErrorIs(t, err, ErrIncompatibleOptions)
+	})
+}
+
+func TestCopyResume(t *testing.T) {
+	from := "testdata/input.txt"
+	expected, err := os.ReadFile("testdata/expected_offset0_limit0.txt")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		partial []byte
+	}{
+		{name: "empty destination", partial: nil},
+		{name: "valid prefix", partial: expected[:1000]},
+		{name: "complete destination", partial: expected},
+		{name: "corrupted prefix", partial: append([]byte("garbage"), expected[7:1000]...)},
+		{name: "destination longer than source", partial: append(append([]byte{}, expected...), "tail"...)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.txt")
+			require.NoError(t, os.WriteFile(to, tc.partial, 0o600))
+
+			_, err := CopyWithOptions(from, to, 0, 0, Options{Resume: true})
+			require.NoError(t, err)
+
+			actual, _ := os.ReadFile(to)
+			require.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("with offset and limit", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		want, _ := os.ReadFile("testdata/expected_offset100_limit1000.txt")
+		require.NoError(t, os.WriteFile(to, want[:500], 0o600))
+
+		_, err := CopyWithOptions(from, to, 100, 1000, Options{Resume: true})
+		require.NoError(t, err)
+
+		actual, _ := os.ReadFile(to)
+		require.Equal(t, want, actual)
+	})
+}
+
+func TestCopyChecksums(t *testing.T) {
+	from := "testdata/input.txt"
+	want := map[HashAlgo]string{
+		HashSHA256: "ed523fc33a33551f7578b51df8ad90d85fb82820456330fb7cda166737dc205d",
+		HashCRC32C: "d7d8d494",
+		HashXXHash: "b7a92bebb1748da5",
+	}
+	all := []HashAlgo{HashSHA256, HashCRC32C, HashXXHash}
+
+	t.Run("digests of copied range", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+
+		result, err := CopyWithOptions(from, to, 100, 1000, Options{Hashes: all, Verify: true})
+		require.NoError(t, err)
+		require.Equal(t, want, result.Digests)
+		require.Equal(t, int64(1000), result.Bytes)
+		require.Equal(t, int64(0), result.Resumed)
+		require.Positive(t, result.Duration)
+		require.Positive(t, result.Throughput)
+	})
+
+	t.Run("resumed copy covers the whole range", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		expected, _ := os.ReadFile("testdata/expected_offset100_limit1000.txt")
+		require.NoError(t, os.WriteFile(to, expected[:400], 0o600))
+
+		result, err := CopyWithOptions(from, to, 100, 1000, Options{Resume: true, Hashes: all})
+		require.NoError(t, err)
+		require.Equal(t, want, result.Digests)
+		require.Equal(t, int64(600), result.Bytes)
+		require.Equal(t, int64(400), result.Resumed)
+	})
+
+	t.Run("verify without hashes uses sha256", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+
+		result, err := CopyWithOptions(from, to, 100, 1000, Options{Atomic: true, Verify: true})
+		require.NoError(t, err)
+		require.Equal(t, map[HashAlgo]string{HashSHA256: want[HashSHA256]}, result.Digests)
+	})
+
+	t.Run("unknown hash", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+
+		_, err := CopyWithOptions(from, to, 0, 0, Options{Hashes: []HashAlgo{"md5"}})
+		require.ErrorIs(t, err, ErrUnknownHash)
+	})
+
+	t.Run("verify detects mismatch", func(t *testing.T) {
+		data, _ := os.ReadFile("testdata/expected_offset100_limit1000.txt")
+		data[500] ^= 0xff
+
+		err := verify(bytes.NewReader(data), int64(len(data)), want)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+}
+
+// withStdio подменяет os.Stdin и os.Stdout на время теста.
+func withStdio(t *testing.T, stdin, stdout *os.File) {
+	t.Helper()
+	origIn, origOut := os.Stdin, os.Stdout
+	if stdin != nil {
+		os.Stdin = stdin
+	}
+	if stdout != nil {
+		os.Stdout = stdout
+	}
+	t.Cleanup(func() {
+		os.Stdin, os.Stdout = origIn, origOut
+	})
+}
+
+func TestCopyStreams(t *testing.T) {
+	input, err := os.ReadFile("testdata/input.txt")
+	require.NoError(t, err)
+
+	// pipe возвращает читающий конец канала, в который записан input
+	pipe := func(t *testing.T) *os.File {
+		t.Helper()
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		go func() {
+			defer w.Close()
+			w.Write(input)
+		}()
+		t.Cleanup(func() { r.Close() })
+		return r
+	}
+
+	tests := []struct {
+		name          string
+		offset, limit int64
+	}{
+		{name: "whole stream"},
+		{name: "offset and limit", offset: 100, limit: 1000},
+		{name: "limit exceeds stream", offset: 6000, limit: 1000},
+		{name: "offset equals stream size", offset: int64(len(input))},
+	}
+
+	for _, tc := range tests {
+		t.Run("from stdin: "+tc.name, func(t *testing.T) {
+			withStdio(t, pipe(t), nil)
+			to := filepath.Join(t.TempDir(), "out.txt")
+
+			result, err := CopyWithOptions(stdio, to, tc.offset, tc.limit, Options{Hashes: []HashAlgo{HashSHA256}})
+			require.NoError(t, err)
+
+			expected := input[tc.offset:]
+			if tc.limit > 0 && tc.limit < int64(len(expected)) {
+				expected = expected[:tc.limit]
+			}
+			actual, _ := os.ReadFile(to)
+			require.Equal(t, expected, actual)
+			require.Equal(t, int64(len(expected)), result.Bytes)
+		})
+	}
+
+	t.Run("offset exceeds stdin size", func(t *testing.T) {
+		withStdio(t, pipe(t), nil)
+
+		err := Copy(stdio, filepath.Join(t.TempDir(), "out.txt"), 7000, 0)
+		require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+	})
+
+	t.Run("to stdout", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		withStdio(t, nil, w)
+
+		var actual []byte
+		read := make(chan struct{})
+		go func() {
+			defer close(read)
+			actual, _ = io.ReadAll(r)
+		}()
+
+		err = Copy("testdata/input.txt", stdio, 100, 1000)
+		w.Close()
+		<-read
+		require.NoError(t, err)
+
+		expected, _ := os.ReadFile("testdata/expected_offset100_limit1000.txt")
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("device with limit", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.bin")
+
+		result, err := CopyWithOptions("/dev/urandom", to, 10, 4096, Options{})
+		require.NoError(t, err)
+		require.Equal(t, int64(4096), result.Bytes)
+
+		info, err := os.Stat(to)
+		require.NoError(t, err)
+		require.Equal(t, int64(4096), info.Size())
+	})
+
+	t.Run("modes requiring seek", func(t *testing.T) {
+		_, err := CopyWithOptions("testdata/input.txt", stdio, 0, 0, Options{Verify: true})
+		require.ErrorIs(t, err, ErrNotSeekable)
+
+		_, err = CopyWithOptions("/dev/urandom", filepath.Join(t.TempDir(), "out.bin"), 0, 10, Options{Resume: true})
+		require.ErrorIs(t, err, ErrNotSeekable)
+	})
 }
@@ -0,0 +1,153 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"golang.org/x/sys/unix"
+)
+
+const fastCopySupported = true
+
+// fastChunk — сколько байт передаётся ядру за один вызов. Ограничение нужно,
+// чтобы счётчик прогресса обновлялся не только в конце большого файла.
+const fastChunk = 8 << 20
+
+type copyMode int
+
+const (
+	modeCopyFileRange copyMode = iota
+	modeSendfile
+	modeUserspace
+)
+
+// copyFast копирует n байт из текущей позиции src в текущую позицию dst
+// средствами ядра: copy_file_range, при его недоступности sendfile, а если
+// не работает и он — через буфер. Дыры источника (SEEK_HOLE) не копируются,
+// а пропускаются в dst, так что разреженные файлы остаются разреженными.
+// Число скопированных байт (включая дыры) накапливается в copied.
+func copyFast(dst, src *os.File, n int64, copied *atomic.Int64) error {
+	srcStart, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("seek source: %w", err)
+	}
+	dstStart, err := dst.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("seek destination: %w", err)
+	}
+
+	srcFd := int(src.Fd())
+	end := srcStart + n
+	mode := modeCopyFileRange
+
+	for pos := srcStart; pos < end; {
+		dataStart, dataEnd := nextData(srcFd, pos, end)
+		copied.Add(dataStart - pos)
+		pos = dataStart
+
+		for pos < dataEnd {
+			chunk := min(dataEnd-pos, fastChunk)
+			written, err := copyChunk(&mode, src, dst, pos, dstStart+pos-srcStart, chunk)
+			if err != nil {
+				return fmt.Errorf("copy: %w", err)
+			}
+			pos += written
+			copied.Add(written)
+		}
+	}
+
+	// Если диапазон заканчивается дырой, размер файла нужно выставить явно
+	info, err := dst.Stat()
+	if err != nil {
+		return fmt.Errorf("stat destination: %w", err)
+	}
+	if size := dstStart + n; info.Size() < size {
+		if err = dst.Truncate(size); err != nil {
+			return fmt.Errorf("truncate destination: %w", err)
+		}
+	}
+
+	// Оставляем позиции файлов такими, как после обычного копирования
+	if _, err = src.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("seek source: %w", err)
+	}
+	if _, err = dst.Seek(dstStart+n, io.SeekStart); err != nil {
+		return fmt.Errorf("seek destination: %w", err)
+	}
+	return nil
+}
+
+// nextData возвращает ближайший к pos участок данных [data, hole) в пределах end.
+// Если файловая система не умеет SEEK_DATA, весь остаток считается данными.
+func nextData(fd int, pos, end int64) (int64, int64) {
+	data, err := unix.Seek(fd, pos, unix.SEEK_DATA)
+	if err != nil {
+		if errors.Is(err, unix.ENXIO) {
+			// После pos данных нет — до конца только дыра
+			return end, end
+		}
+		return pos, end
+	}
+	if data >= end {
+		return end, end
+	}
+
+	hole, err := unix.Seek(fd, data, unix.SEEK_HOLE)
+	if err != nil || hole > end {
+		hole = end
+	}
+	return data, hole
+}
+
+// copyChunk копирует до size байт со смещения srcOff в dstOff, понижая mode,
+// если текущий способ не поддерживается для этой пары файлов.
+func copyChunk(mode *copyMode, src, dst *os.File, srcOff, dstOff, size int64) (int64, error) {
+	for {
+		var written int
+		var err error
+
+		switch *mode {
+		case modeCopyFileRange:
+			roff, woff := srcOff, dstOff
+			written, err = unix.CopyFileRange(int(src.Fd()), &roff, int(dst.Fd()), &woff, int(size), 0)
+		case modeSendfile:
+			// sendfile пишет в текущую позицию dst
+			if _, err = dst.Seek(dstOff, io.SeekStart); err != nil {
+				return 0, err
+			}
+			roff := srcOff
+			written, err = unix.Sendfile(int(dst.Fd()), int(src.Fd()), &roff, int(size))
+		case modeUserspace:
+			n, err := io.Copy(io.NewOffsetWriter(dst, dstOff), io.NewSectionReader(src, srcOff, size))
+			if err == nil && n < size {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+
+		if err != nil {
+			if isUnsupported(err) {
+				*mode++
+				continue
+			}
+			return 0, err
+		}
+		if written == 0 {
+			// Источник оказался короче, чем при Stat
+			return 0, io.ErrUnexpectedEOF
+		}
+		return int64(written), nil
+	}
+}
+
+func isUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) ||
+		errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.EOPNOTSUPP)
+}
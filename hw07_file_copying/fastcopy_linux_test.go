@@ -0,0 +1,177 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeSparse создаёт файл size байт с данными только в начале и в середине.
+func makeSparse(t testing.TB, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sparse.bin")
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.Truncate(size))
+	data := bytes.Repeat([]byte("data"), 1024)
+	_, err = f.WriteAt(data, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt(data, size/2)
+	require.NoError(t, err)
+
+	return path
+}
+
+// allocated возвращает реально занятое файлом место на диске.
+func allocated(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+func TestCopySparse(t *testing.T) {
+	const size = 16 << 20
+	from := makeSparse(t, size)
+	if allocated(t, from) >= size {
+		t.Skip("filesystem doesn't support sparse files")
+	}
+
+	tests := []struct {
+		name          string
+		offset, limit int64
+	}{
+		{name: "whole file"},
+		{name: "ends with hole", limit: size/2 + 100},
+		{name: "starts in hole", offset: 100 << 10},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.bin")
+
+			err := Copy(from, to, tc.offset, tc.limit)
+			require.NoError(t, err)
+
+			expected, _ := os.ReadFile(from)
+			expected = expected[tc.offset:]
+			if tc.limit > 0 {
+				expected = expected[:tc.limit]
+			}
+			actual, _ := os.ReadFile(to)
+			require.Equal(t, expected, actual)
+
+			require.Less(t, allocated(t, to), int64(size/4), "destination is not sparse")
+		})
+	}
+}
+
+func TestCopySparseToFIFO(t *testing.T) {
+	const size = 1 << 20
+	from := makeSparse(t, size)
+	fifo := filepath.Join(t.TempDir(), "fifo")
+	require.NoError(t, syscall.Mkfifo(fifo, 0o600))
+
+	// В FIFO дыры источника должны прийти нулями
+	var actual []byte
+	read := make(chan error, 1)
+	go func() {
+		var err error
+		actual, err = os.ReadFile(fifo)
+		read <- err
+	}()
+
+	require.NoError(t, Copy(from, fifo, 0, 0))
+	require.NoError(t, <-read)
+
+	expected, _ := os.ReadFile(from)
+	require.Equal(t, expected, actual)
+}
+
+func TestCopyChunkFallback(t *testing.T) {
+	from := "testdata/input.txt"
+	expected, _ := os.ReadFile(from)
+
+	for _, mode := range []copyMode{modeCopyFileRange, modeSendfile, modeUserspace} {
+		src, err := os.Open(from)
+		require.NoError(t, err)
+		defer src.Close()
+
+		to := filepath.Join(t.TempDir(), "out.txt")
+		dst, err := os.Create(to)
+		require.NoError(t, err)
+		defer dst.Close()
+
+		var copied int64
+		for copied < int64(len(expected)) {
+			n, err := copyChunk(&mode, src, dst, copied, copied, min(1000, int64(len(expected))-copied))
+			require.NoError(t, err)
+			copied += n
+		}
+
+		actual, _ := os.ReadFile(to)
+		require.Equal(t, expected, actual)
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	const size = 64 << 20
+	dir := b.TempDir()
+	from := filepath.Join(dir, "input.bin")
+	require.NoError(b, os.WriteFile(from, bytes.Repeat([]byte("0123456789abcdef"), size/16), 0o600))
+
+	run := func(b *testing.B, copyFn func(dst, src *os.File) error) {
+		b.Helper()
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			src, err := os.Open(from)
+			require.NoError(b, err)
+			dst, err := os.Create(filepath.Join(dir, "out.bin"))
+			require.NoError(b, err)
+
+			require.NoError(b, copyFn(dst, src))
+
+			src.Close()
+			dst.Close()
+		}
+	}
+
+	b.Run("stream", func(b *testing.B) {
+		run(b, func(dst, src *os.File) error {
+			return copyStream(dst, src, size, SilentProgress{})
+		})
+	})
+
+	b.Run("fast", func(b *testing.B) {
+		run(b, func(dst, src *os.File) error {
+			return copyFastWithProgress(dst, src, size, SilentProgress{})
+		})
+	})
+
+	b.Run("fast sparse", func(b *testing.B) {
+		sparse := makeSparse(b, size)
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			src, err := os.Open(sparse)
+			require.NoError(b, err)
+			dst, err := os.Create(filepath.Join(dir, "out.bin"))
+			require.NoError(b, err)
+
+			var copied atomic.Int64
+			require.NoError(b, copyFast(dst, src, size, &copied))
+
+			src.Close()
+			dst.Close()
+		}
+	})
+}
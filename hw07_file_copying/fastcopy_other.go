@@ -0,0 +1,16 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+)
+
+const fastCopySupported = false
+
+// copyFast на других платформах не используется: копирование идёт через буфер.
+func copyFast(_, _ *os.File, _ int64, _ *atomic.Int64) error {
+	return errors.ErrUnsupported
+}
@@ -3,21 +3,138 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 )
 
 var (
-	from, to      string
-	limit, offset int64
+	from, to           string
+	limit, offset      int64
+	atomicMode, resume bool
+	hashes             string
+	verifyDst          bool
+	recursive          bool
+	followSymlinks     bool
+	include, exclude   string
+	workers            int
+	bwlimit            string
+	progressMode       string
 )
 
 func init() {
-	flag.StringVar(&from, "from", "", "file to read from")
-	flag.StringVar(&to, "to", "", "file to write to")
+	flag.StringVar(&from, "from", "", "file to read from, - for stdin")
+	flag.StringVar(&to, "to", "", "file to write to, - for stdout")
 	flag.Int64Var(&limit, "limit", 0, "limit of bytes to copy")
 	flag.Int64Var(&offset, "offset", 0, "offset in input file")
+	flag.BoolVar(&atomicMode, "atomic", false, "write to a temp file and rename it when done")
+	flag.BoolVar(&resume, "resume", false, "continue a partially copied destination file")
+	flag.StringVar(&hashes, "hash", "", "comma-separated checksums of copied data: sha256, crc32c, xxhash")
+	flag.BoolVar(&verifyDst, "verify", false, "re-read destination and verify checksums")
+	flag.BoolVar(&recursive, "r", false, "copy directory tree")
+	flag.BoolVar(&followSymlinks, "L", false, "follow symlinks in directory tree instead of copying them")
+	flag.StringVar(&include, "include", "", "comma-separated glob patterns of files to copy from directory tree")
+	flag.StringVar(&exclude, "exclude", "", "comma-separated glob patterns of files to skip in directory tree")
+	flag.IntVar(&workers, "workers", 1, "number of files copied in parallel from directory tree")
+	flag.StringVar(&bwlimit, "bwlimit", "", "write bandwidth limit in bytes per second, K, M or G suffix allowed")
+	flag.StringVar(&progressMode, "progress", "bar", "progress output: bar, none or json (lines on stderr)")
 }
 
 func main() {
 	flag.Parse()
-	fmt.Println(from, to, offset, limit)
+
+	if from == "" || to == "" {
+		fmt.Fprintln(os.Stderr, "go-cp: both -from and -to are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var bandwidth int64
+	var err error
+	if bwlimit != "" {
+		if bandwidth, err = ParseBandwidth(bwlimit); err != nil {
+			fmt.Fprintln(os.Stderr, "go-cp:", err)
+			os.Exit(2)
+		}
+	}
+	progress, err := newProgress(progressMode, to == stdio)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-cp:", err)
+		os.Exit(2)
+	}
+
+	if recursive {
+		copyTree(bandwidth, progress)
+		return
+	}
+
+	opts := Options{
+		Atomic:         atomicMode,
+		Resume:         resume,
+		Verify:         verifyDst,
+		BandwidthLimit: bandwidth,
+		Progress:       progress,
+	}
+	for _, algo := range splitList(hashes) {
+		opts.Hashes = append(opts.Hashes, HashAlgo(algo))
+	}
+
+	result, err := CopyWithOptions(from, to, offset, limit, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-cp:", err)
+		os.Exit(1)
+	}
+
+	for _, algo := range opts.Hashes {
+		fmt.Fprintf(os.Stderr, "%s  %s\n", result.Digests[algo], algo)
+	}
+}
+
+func copyTree(bandwidth int64, progress ProgressReporter) {
+	if offset != 0 || limit != 0 {
+		fmt.Fprintln(os.Stderr, "go-cp: -offset and -limit can't be used with -r")
+		os.Exit(2)
+	}
+
+	opts := TreeOptions{
+		FollowSymlinks: followSymlinks,
+		Include:        splitList(include),
+		Exclude:        splitList(exclude),
+		Workers:        workers,
+		BandwidthLimit: bandwidth,
+		Progress:       progress,
+	}
+	if _, err := CopyTree(from, to, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "go-cp:", err)
+		os.Exit(1)
+	}
+}
+
+// jsonProgressInterval — как часто выводятся строки прогресса в режиме json.
+const jsonProgressInterval = time.Second
+
+// newProgress создаёт репортёр для режима -progress.
+func newProgress(mode string, toStdout bool) (ProgressReporter, error) {
+	switch mode {
+	case "bar":
+		return defaultProgress(toStdout), nil
+	case "none":
+		return SilentProgress{}, nil
+	case "json":
+		return NewJSONProgress(os.Stderr, jsonProgressInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q", mode)
+	}
+}
+
+// splitList разбирает список значений через запятую.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
 }
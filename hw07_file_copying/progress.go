@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb"
+)
+
+// ProgressReporter получает сведения о ходе копирования.
+// Методы могут вызываться из нескольких горутин одновременно.
+type ProgressReporter interface {
+	// Start вызывается перед копированием; total <= 0 — размер неизвестен.
+	Start(total int64)
+	// Add сообщает, что скопировано ещё n байт.
+	Add(n int64)
+	// Set задаёт общее количество скопированных байт.
+	Set(current int64)
+	// Finish вызывается после окончания копирования, в том числе неудачного.
+	Finish()
+}
+
+// progressReader увеличивает прогресс на каждый прочитанный байт.
+type progressReader struct {
+	r        io.Reader
+	progress ProgressReporter
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.progress.Add(int64(n))
+	return n, err
+}
+
+// BarProgress выводит прогресс-бар в терминал.
+type BarProgress struct {
+	out io.Writer
+	bar *pb.ProgressBar
+}
+
+// NewBarProgress создаёт прогресс-бар, который пишет в out.
+func NewBarProgress(out io.Writer) *BarProgress {
+	return &BarProgress{out: out}
+}
+
+// Start создаёт бар. Если total неизвестен, выводятся только количество
+// скопированных байт и скорость.
+func (b *BarProgress) Start(total int64) {
+	bar := pb.New64(total)
+	if total <= 0 {
+		bar.SetUnits(pb.U_BYTES)
+		bar.ShowPercent = false
+		bar.ShowBar = false
+		bar.ShowTimeLeft = false
+		bar.ShowSpeed = true
+	}
+	bar.Output = b.out
+	b.bar = bar.Start()
+}
+
+func (b *BarProgress) Add(n int64) {
+	b.bar.Add64(n)
+}
+
+func (b *BarProgress) Set(current int64) {
+	b.bar.Set64(current)
+}
+
+func (b *BarProgress) Finish() {
+	b.bar.Finish()
+}
+
+// SilentProgress ничего не выводит.
+type SilentProgress struct{}
+
+func (SilentProgress) Start(int64) {}
+func (SilentProgress) Add(int64)   {}
+func (SilentProgress) Set(int64)   {}
+func (SilentProgress) Finish()     {}
+
+// progressEvent — строка машиночитаемого прогресса.
+type progressEvent struct {
+	Event       string  `json:"event"`
+	Bytes       int64   `json:"bytes"`
+	Total       int64   `json:"total,omitempty"`
+	Percent     float64 `json:"percent,omitempty"`
+	BytesPerSec float64 `json:"bytesPerSec"`
+}
+
+// JSONProgress пишет прогресс строками JSON не чаще раза в interval:
+//
+//	{"event":"progress","bytes":1024,"total":4096,"percent":25,"bytesPerSec":2048}
+type JSONProgress struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	interval time.Duration
+	total    int64
+	current  int64
+	started  time.Time
+	lastSent time.Time
+}
+
+// NewJSONProgress создаёт репортёр, пишущий в out.
+func NewJSONProgress(out io.Writer, interval time.Duration) *JSONProgress {
+	return &JSONProgress{enc: json.NewEncoder(out), interval: interval}
+}
+
+func (j *JSONProgress) Start(total int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.total = total
+	j.current = 0
+	j.started = time.Now()
+	j.lastSent = j.started
+	j.emit("start")
+}
+
+func (j *JSONProgress) Add(n int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.current += n
+	j.maybeEmit()
+}
+
+func (j *JSONProgress) Set(current int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.current = current
+	j.maybeEmit()
+}
+
+func (j *JSONProgress) Finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.emit("finish")
+}
+
+func (j *JSONProgress) maybeEmit() {
+	if now := time.Now(); now.Sub(j.lastSent) >= j.interval {
+		j.lastSent = now
+		j.emit("progress")
+	}
+}
+
+func (j *JSONProgress) emit(event string) {
+	e := progressEvent{Event: event, Bytes: j.current, Total: j.total}
+	if j.total > 0 {
+		e.Percent = float64(j.current) * 100 / float64(j.total)
+	}
+	if elapsed := time.Since(j.started).Seconds(); elapsed > 0 {
+		e.BytesPerSec = float64(j.current) / elapsed
+	}
+	// Ошибка записи прогресса не должна прерывать копирование
+	_ = j.enc.Encode(e)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordProgress запоминает вызовы репортёра.
+type recordProgress struct {
+	mu       sync.Mutex
+	total    int64
+	current  int64
+	started  bool
+	finished bool
+}
+
+func (r *recordProgress) Start(total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = true
+	r.total = total
+}
+
+func (r *recordProgress) Add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current += n
+}
+
+func (r *recordProgress) Set(current int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = current
+}
+
+func (r *recordProgress) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = true
+}
+
+func TestProgressReporter(t *testing.T) {
+	from := "testdata/input.txt"
+	info, err := os.Stat(from)
+	require.NoError(t, err)
+
+	t.Run("file copy", func(t *testing.T) {
+		p := &recordProgress{}
+		_, err := CopyWithOptions(from, filepath.Join(t.TempDir(), "out.txt"), 100, 1000, Options{Progress: p})
+		require.NoError(t, err)
+		require.True(t, p.started)
+		require.True(t, p.finished)
+		require.Equal(t, int64(1000), p.total)
+		require.Equal(t, int64(1000), p.current)
+	})
+
+	t.Run("resumed copy", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		expected, _ := os.ReadFile(from)
+		require.NoError(t, os.WriteFile(to, expected[:500], 0o600))
+
+		p := &recordProgress{}
+		_, err := CopyWithOptions(from, to, 0, 0, Options{Resume: true, Progress: p})
+		require.NoError(t, err)
+		require.Equal(t, info.Size(), p.current)
+	})
+
+	t.Run("tree copy", func(t *testing.T) {
+		p := &recordProgress{}
+		_, err := CopyTree(makeTree(t), filepath.Join(t.TempDir(), "dst"), TreeOptions{Workers: 3, Progress: p})
+		require.NoError(t, err)
+		require.Equal(t, int64(33), p.total)
+		require.Equal(t, int64(33), p.current)
+		require.True(t, p.finished)
+	})
+}
+
+func TestJSONProgress(t *testing.T) {
+	var out bytes.Buffer
+	from := "testdata/input.txt"
+	info, err := os.Stat(from)
+	require.NoError(t, err)
+
+	// Нулевой интервал — строка на каждое обновление
+	p := NewJSONProgress(&out, 0)
+	opts := Options{Hashes: []HashAlgo{HashSHA256}, Progress: p}
+	_, err = CopyWithOptions(from, filepath.Join(t.TempDir(), "out.txt"), 0, 0, opts)
+	require.NoError(t, err)
+
+	var events []progressEvent
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var e progressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.GreaterOrEqual(t, len(events), 3)
+
+	first, last := events[0], events[len(events)-1]
+	require.Equal(t, "start", first.Event)
+	require.Equal(t, info.Size(), first.Total)
+	require.Equal(t, "progress", events[1].Event)
+	require.Equal(t, "finish", last.Event)
+	require.Equal(t, info.Size(), last.Bytes)
+	require.InDelta(t, 100, last.Percent, 0.001)
+}
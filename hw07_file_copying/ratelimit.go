@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidBandwidth = errors.New("invalid bandwidth limit")
+
+// tokenBucket ограничивает скорость rate байт/с, допуская всплески до burst байт.
+// Один bucket может разделяться несколькими писателями.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64) *tokenBucket {
+	// Всплеск — десятая доля секунды, чтобы скорость была ровной
+	burst := max(rate/10, 1)
+	return &tokenBucket{rate: float64(rate), burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// wait забирает n <= burst токенов, при нехватке засыпая до их появления.
+func (b *tokenBucket) wait(n int64) {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, float64(b.burst))
+	b.last = now
+	// Токены резервируются сразу, поэтому следующий писатель встанет в очередь за нами
+	b.tokens -= float64(n)
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit > 0 {
+		time.Sleep(time.Duration(deficit / b.rate * float64(time.Second)))
+	}
+}
+
+// limitedWriter пишет в w со скоростью, ограниченной bucket.
+type limitedWriter struct {
+	w      io.Writer
+	bucket *tokenBucket
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(int64(len(p)), lw.bucket.burst)]
+		lw.bucket.wait(int64(len(chunk)))
+		n, err := lw.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// ParseBandwidth разбирает скорость в байтах в секунду с необязательным
+// суффиксом K, M или G (степени 1024): "512K", "10M".
+func ParseBandwidth(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	multiplier := int64(1)
+	if s != "" {
+		switch strings.ToUpper(s[len(s)-1:]) {
+		case "K":
+			multiplier = 1 << 10
+		case "M":
+			multiplier = 1 << 20
+		case "G":
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("%q: %w", value, ErrInvalidBandwidth)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "1000", expected: 1000},
+		{input: "512K", expected: 512 << 10},
+		{input: "10m", expected: 10 << 20},
+		{input: " 2G ", expected: 2 << 30},
+		{input: "0", expected: 0},
+		{input: "8589934591G", expected: 8589934591 << 30},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			n, err := ParseBandwidth(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, n)
+		})
+	}
+
+	for _, input := range []string{"", "K", "fast", "-1M", "1.5M", "9223372036854775807K", "8589934592G"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseBandwidth(input)
+			require.ErrorIs(t, err, ErrInvalidBandwidth)
+		})
+	}
+}
+
+func TestLimitedWriter(t *testing.T) {
+	const rate = 20 << 10
+	data := bytes.Repeat([]byte("x"), rate/2)
+
+	var out bytes.Buffer
+	w := &limitedWriter{w: &out, bucket: newTokenBucket(rate)}
+
+	start := time.Now()
+	n, err := w.Write(data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), n)
+	require.Equal(t, data, out.Bytes())
+
+	// Полсекунды данных за вычетом начального запаса в 0.1 с
+	require.GreaterOrEqual(t, time.Since(start), 350*time.Millisecond)
+}
+
+func TestCopyBandwidthLimit(t *testing.T) {
+	from := "testdata/input.txt"
+	info, err := os.Stat(from)
+	require.NoError(t, err)
+
+	t.Run("file", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		rate := info.Size() * 2
+
+		result, err := CopyWithOptions(from, to, 0, 0, Options{BandwidthLimit: rate, Progress: SilentProgress{}})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, result.Duration, 350*time.Millisecond)
+
+		expected, _ := os.ReadFile(from)
+		actual, _ := os.ReadFile(to)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("tree shares the limit", func(t *testing.T) {
+		start := time.Now()
+		_, err := CopyTree(makeTree(t), filepath.Join(t.TempDir(), "dst"),
+			TreeOptions{Workers: 3, BandwidthLimit: 60, Progress: SilentProgress{}})
+		require.NoError(t, err)
+		// 33 байта при 60 байт/с и запасе в 6 байт
+		require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := CopyWithOptions(from, filepath.Join(t.TempDir(), "out.txt"), 0, 0, Options{BandwidthLimit: -1})
+		require.ErrorIs(t, err, ErrInvalidBandwidth)
+	})
+}
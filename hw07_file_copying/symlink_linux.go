@@ -0,0 +1,15 @@
+//go:build linux
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// lchtimes выставляет время доступа и модификации самой ссылки, не переходя по ней.
+func lchtimes(path string, mtime time.Time) error {
+	tv := unix.NsecToTimeval(mtime.UnixNano())
+	return unix.Lutimes(path, []unix.Timeval{tv, tv})
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "time"
+
+// lchtimes на других платформах ничего не делает: время ссылки не переносится.
+func lchtimes(_ string, _ time.Time) error {
+	return nil
+}
@@ -21,5 +21,36 @@ cmp out.txt testdata/out_offset100_limit1000.txt
 ./go-cp -from testdata/input.txt -to out.txt -offset 6000 -limit 1000
 cmp out.txt testdata/out_offset6000_limit1000.txt
 
-rm -f go-cp out.txt
+if ./go-cp -from testdata/input.txt -to out.txt -offset 7000; then
+  echo "expected error for offset exceeding file size"
+  exit 1
+fi
+
+if ./go-cp -from testdata -to out.txt; then
+  echo "expected error for unsupported file"
+  exit 1
+fi
+
+./go-cp -from /dev/urandom -to out.txt -limit 1000
+test "$(wc -c < out.txt)" -eq 1000
+
+./go-cp -from - -to - -offset 100 -limit 1000 < testdata/input.txt > out.txt
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+cat testdata/input.txt | ./go-cp -from - -to out.txt -offset 6000 -limit 1000
+cmp out.txt testdata/out_offset6000_limit1000.txt
+
+./go-cp -from testdata/input.txt -to out.txt -bwlimit 64K -progress none
+cmp out.txt testdata/out_offset0_limit0.txt
+
+./go-cp -from testdata/input.txt -to out.txt -progress json 2> progress.json
+cmp out.txt testdata/out_offset0_limit0.txt
+tail -n 1 progress.json | grep -q '"event":"finish"'
+
+if ./go-cp -from testdata/input.txt -to out.txt -bwlimit fast; then
+  echo "expected error for invalid bandwidth limit"
+  exit 1
+fi
+
+rm -f go-cp out.txt progress.json
 echo "PASS"
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotDirectory = errors.New("source is not a directory")
+	ErrSymlinkLoop  = errors.New("symlink loop")
+)
+
+// TreeOptions задаёт параметры копирования дерева каталогов.
+type TreeOptions struct {
+	// FollowSymlinks — копировать то, на что указывают ссылки, а не сами ссылки.
+	FollowSymlinks bool
+	// Include — glob-шаблоны копируемых файлов (filepath.Match). Пусто — все файлы.
+	// Шаблон без "/" сравнивается с именем файла, с "/" — с путём относительно корня.
+	Include []string
+	// Exclude — glob-шаблоны пропускаемых файлов и каталогов, в том же формате.
+	Exclude []string
+	// Workers — число параллельно копируемых файлов, по умолчанию 1.
+	Workers int
+	// BandwidthLimit — общее для всех файлов ограничение скорости записи, байт/с.
+	BandwidthLimit int64
+	// Progress получает ход копирования. По умолчанию выводится прогресс-бар.
+	Progress ProgressReporter
+}
+
+// TreeResult — итог копирования дерева.
+type TreeResult struct {
+	Dirs     int
+	Files    int
+	Symlinks int
+	Bytes    int64
+}
+
+type entryKind int
+
+const (
+	kindDir entryKind = iota
+	kindFile
+	kindSymlink
+)
+
+// treeEntry — элемент дерева, который нужно воспроизвести в назначении.
+type treeEntry struct {
+	kind entryKind
+	rel  string
+	src  string
+	info os.FileInfo
+}
+
+// CopyTree рекурсивно копирует каталог src в dst, сохраняя права доступа,
+// время модификации и (если не задан FollowSymlinks) символические ссылки.
+// Время модификации самих ссылок переносится только на Linux.
+// С фильтром Include создаются только каталоги, в которые попал хотя бы один файл.
+func CopyTree(src, dst string, opts TreeOptions) (TreeResult, error) {
+	var result TreeResult
+
+	if opts.BandwidthLimit < 0 {
+		return result, fmt.Errorf("%d: %w", opts.BandwidthLimit, ErrInvalidBandwidth)
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return result, fmt.Errorf("stat source: %w", err)
+	}
+	if !info.IsDir() {
+		return result, fmt.Errorf("%s: %w", src, ErrNotDirectory)
+	}
+
+	entries := []treeEntry{{kind: kindDir, rel: ".", src: src, info: info}}
+	entries, err = walkTree(src, "", opts, entries, []os.FileInfo{info})
+	if err != nil {
+		return result, err
+	}
+
+	var files []treeEntry
+	var total int64
+	for _, e := range entries {
+		target := filepath.Join(dst, e.rel)
+		switch e.kind {
+		case kindDir:
+			// Права каталога выставляем в конце, чтобы можно было писать внутрь
+			if err = os.MkdirAll(target, 0o700); err != nil {
+				return result, fmt.Errorf("create directory: %w", err)
+			}
+			result.Dirs++
+		case kindSymlink:
+			if err = copySymlink(e.src, target, e.info.ModTime()); err != nil {
+				return result, err
+			}
+			result.Symlinks++
+		case kindFile:
+			files = append(files, e)
+			total += e.info.Size()
+		}
+	}
+
+	progress := opts.Progress
+	if progress == nil {
+		progress = defaultProgress(false)
+	}
+	var bucket *tokenBucket
+	if opts.BandwidthLimit > 0 {
+		bucket = newTokenBucket(opts.BandwidthLimit)
+	}
+
+	progress.Start(total)
+	err = copyFiles(files, dst, opts.Workers, bucket, progress)
+	progress.Finish()
+	if err != nil {
+		return result, err
+	}
+	result.Files = len(files)
+	result.Bytes = total
+
+	// Каталоги обходим с конца: создание вложенных элементов меняет mtime родителя
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e := entries[i]; e.kind == kindDir {
+			if err = applyMeta(filepath.Join(dst, e.rel), e.info); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// walkTree собирает элементы каталога dir рекурсивно.
+// stack — цепочка каталогов от корня, нужна для обнаружения циклов по ссылкам.
+func walkTree(dir, rel string, opts TreeOptions, entries []treeEntry, stack []os.FileInfo) ([]treeEntry, error) {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	for _, item := range items {
+		path := filepath.Join(dir, item.Name())
+		relPath := filepath.Join(rel, item.Name())
+		if matchAny(opts.Exclude, relPath) {
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", path, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !opts.FollowSymlinks {
+				if len(opts.Include) == 0 || matchAny(opts.Include, relPath) {
+					entries = append(entries, treeEntry{kind: kindSymlink, rel: relPath, src: path, info: info})
+				}
+				continue
+			}
+			if info, err = os.Stat(path); err != nil {
+				return nil, fmt.Errorf("follow symlink %s: %w", path, err)
+			}
+		}
+
+		switch {
+		case info.IsDir():
+			for _, parent := range stack {
+				if os.SameFile(parent, info) {
+					return nil, fmt.Errorf("%s: %w", path, ErrSymlinkLoop)
+				}
+			}
+			entries = append(entries, treeEntry{kind: kindDir, rel: relPath, src: path, info: info})
+			n := len(entries)
+			entries, err = walkTree(path, relPath, opts, entries, append(stack, info))
+			if err != nil {
+				return nil, err
+			}
+			// С фильтром Include каталог без подходящих файлов не создаём
+			if len(opts.Include) > 0 && len(entries) == n {
+				entries = entries[:n-1]
+			}
+		case info.Mode().IsRegular():
+			if len(opts.Include) == 0 || matchAny(opts.Include, relPath) {
+				entries = append(entries, treeEntry{kind: kindFile, rel: relPath, src: path, info: info})
+			}
+		default:
+			return nil, fmt.Errorf("%s: %w", path, ErrUnsupportedFile)
+		}
+	}
+	return entries, nil
+}
+
+// matchAny проверяет путь по glob-шаблонам.
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(rel)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFiles копирует файлы в workers горутин и останавливается на первой ошибке.
+// Если bucket не nil, скорость записи всех файлов ограничивается им.
+func copyFiles(files []treeEntry, dst string, workers int, bucket *tokenBucket, progress ProgressReporter) error {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	tasks := make(chan treeEntry)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for e := range tasks {
+				if err := copyTreeFile(e, filepath.Join(dst, e.rel), bucket, progress); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, e := range files {
+		if failed() {
+			break
+		}
+		tasks <- e
+	}
+	close(tasks)
+
+	wg.Wait()
+	return firstErr
+}
+
+func copyTreeFile(e treeEntry, target string, bucket *tokenBucket, progress ProgressReporter) error {
+	src, err := os.Open(e.src)
+	if err != nil {
+		return fmt.Errorf("open source: %w", err)
+	}
+	defer src.Close()
+
+	// Существующий файл мог остаться от прошлого копирования с правами только
+	// на чтение, поэтому не перезаписываем его, а создаём заново
+	if err = os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("replace destination: %w", err)
+	}
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create destination: %w", err)
+	}
+	defer dst.Close()
+
+	var w io.Writer = dst
+	if bucket != nil {
+		w = &limitedWriter{w: dst, bucket: bucket}
+	}
+	if _, err = io.Copy(w, &progressReader{r: src, progress: progress}); err != nil {
+		return fmt.Errorf("copy %s: %w", e.rel, err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("close destination: %w", err)
+	}
+	return applyMeta(target, e.info)
+}
+
+// copySymlink воссоздаёт ссылку с тем же содержимым и временем модификации.
+func copySymlink(src, target string, mtime time.Time) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("read symlink: %w", err)
+	}
+	if err = os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("replace symlink: %w", err)
+	}
+	if err = os.Symlink(link, target); err != nil {
+		return fmt.Errorf("create symlink: %w", err)
+	}
+	if err = lchtimes(target, mtime); err != nil {
+		return fmt.Errorf("symlink times: %w", err)
+	}
+	return nil
+}
+
+// applyMeta переносит права доступа и время модификации.
+func applyMeta(path string, info os.FileInfo) error {
+	if err := os.Chmod(path, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("chmod: %w", err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		return fmt.Errorf("chtimes: %w", err)
+	}
+	return nil
+}
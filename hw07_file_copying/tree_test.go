@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeTree создаёт тестовое дерево:
+//
+//	a.txt, b.log, bin/run.sh (0755), docs/readme.txt, docs/skip/x.txt, link.txt -> a.txt
+func makeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	files := map[string]string{
+		"a.txt":           "alpha",
+		"b.log":           "bravo",
+		"bin/run.sh":      "#!/bin/sh\n",
+		"docs/readme.txt": "readme",
+		"docs/skip/x.txt": "skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	require.NoError(t, os.Chmod(filepath.Join(root, "bin/run.sh"), 0o755))
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(root, "link.txt")))
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "a.txt"), mtime, mtime))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "docs"), mtime, mtime))
+
+	return root
+}
+
+func TestCopyTree(t *testing.T) {
+	t.Run("preserves metadata and symlinks", func(t *testing.T) {
+		src := makeTree(t)
+		dst := filepath.Join(t.TempDir(), "copy")
+
+		result, err := CopyTree(src, dst, TreeOptions{Workers: 3})
+		require.NoError(t, err)
+		require.Equal(t, TreeResult{Dirs: 4, Files: 5, Symlinks: 1, Bytes: 33}, result)
+
+		content, err := os.ReadFile(filepath.Join(dst, "docs/readme.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "readme", string(content))
+
+		info, err := os.Stat(filepath.Join(dst, "bin/run.sh"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+		for _, name := range []string{"a.txt", "docs"} {
+			srcInfo, err := os.Stat(filepath.Join(src, name))
+			require.NoError(t, err)
+			dstInfo, err := os.Stat(filepath.Join(dst, name))
+			require.NoError(t, err)
+			require.True(t, srcInfo.ModTime().Equal(dstInfo.ModTime()), name)
+		}
+
+		link, err := os.Readlink(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "a.txt", link)
+	})
+
+	t.Run("follow symlinks", func(t *testing.T) {
+		src := makeTree(t)
+		dst := filepath.Join(t.TempDir(), "copy")
+
+		result, err := CopyTree(src, dst, TreeOptions{FollowSymlinks: true})
+		require.NoError(t, err)
+		require.Equal(t, 0, result.Symlinks)
+
+		info, err := os.Lstat(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.True(t, info.Mode().IsRegular())
+
+		content, err := os.ReadFile(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "alpha", string(content))
+	})
+
+	t.Run("include and exclude", func(t *testing.T) {
+		src := makeTree(t)
+		dst := filepath.Join(t.TempDir(), "copy")
+
+		_, err := CopyTree(src, dst, TreeOptions{Include: []string{"*.txt"}, Exclude: []string{"docs/skip", "link.txt"}})
+		require.NoError(t, err)
+
+		for _, name := range []string{"a.txt", "docs/readme.txt"} {
+			require.FileExists(t, filepath.Join(dst, name))
+		}
+		for _, name := range []string{"b.log", "bin", "docs/skip", "link.txt"} {
+			_, err := os.Lstat(filepath.Join(dst, name))
+			require.ErrorIs(t, err, os.ErrNotExist, name)
+		}
+	})
+
+	t.Run("symlink mtime", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("symlink times are preserved only on linux")
+		}
+		src := makeTree(t)
+		mtime := time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC)
+		require.NoError(t, lchtimes(filepath.Join(src, "link.txt"), mtime))
+		dst := filepath.Join(t.TempDir(), "copy")
+
+		_, err := CopyTree(src, dst, TreeOptions{})
+		require.NoError(t, err)
+
+		info, err := os.Lstat(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(info.ModTime()))
+	})
+
+	t.Run("overwrites read-only files", func(t *testing.T) {
+		src := makeTree(t)
+		require.NoError(t, os.Chmod(filepath.Join(src, "a.txt"), 0o444))
+		dst := filepath.Join(t.TempDir(), "copy")
+
+		_, err := CopyTree(src, dst, TreeOptions{})
+		require.NoError(t, err)
+		require.NoError(t, os.Chmod(filepath.Join(src, "a.txt"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("changed"), 0o644))
+		require.NoError(t, os.Chmod(filepath.Join(src, "a.txt"), 0o444))
+
+		_, err = CopyTree(src, dst, TreeOptions{})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(dst, "a.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "changed", string(content))
+	})
+
+	t.Run("symlink loop", func(t *testing.T) {
+		src := makeTree(t)
+		require.NoError(t, os.Symlink("..", filepath.Join(src, "docs/up")))
+
+		_, err := CopyTree(src, filepath.Join(t.TempDir(), "copy"), TreeOptions{FollowSymlinks: true})
+		require.ErrorIs(t, err, ErrSymlinkLoop)
+	})
+
+	t.Run("source is a file", func(t *testing.T) {
+		_, err := CopyTree("testdata/input.txt", filepath.Join(t.TempDir(), "copy"), TreeOptions{})
+		require.ErrorIs(t, err, ErrNotDirectory)
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// commands — подкоманды утилиты, выбираются первым аргументом.
+var commands = map[string]func(args []string) int{
+	"export": runExport,
+	"diff":   runDiff,
+}
+
+// runExport записывает каталог envdir из текущего окружения или dotenv-файла.
+// Переменные окружения, которые нельзя сохранить в envdir, пропускаются
+// с предупреждением; в dotenv-файле такая переменная считается ошибкой.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := fs.String("f", "", "read variables from dotenv `file` instead of the current environment")
+	prefix := fs.String("prefix", "", "export only variables with this prefix")
+	unset := fs.String("unset", "", "comma-separated variables to remove, written as empty files")
+	prune := fs.Bool("prune", false, "remove files of other variables from the directory")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export [flags] <env-dir>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	var env Environment
+	if *file != "" {
+		var err error
+		if env, err = ReadDotenv(*file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading env: %v\n", err)
+			return 1
+		}
+	} else {
+		env = environment(os.Environ())
+	}
+
+	for name, value := range env {
+		if !strings.HasPrefix(name, *prefix) {
+			delete(env, name)
+			continue
+		}
+		if *file != "" {
+			continue
+		}
+		if err := checkWritable(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %v\n", err)
+			delete(env, name)
+		}
+	}
+	if *unset != "" {
+		for _, name := range strings.Split(*unset, ",") {
+			env[strings.TrimSpace(name)] = EnvValue{NeedRemove: true}
+		}
+	}
+
+	if err := WriteDir(fs.Arg(0), env, WriteOptions{Prune: *prune}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing env dir: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runDiff сравнивает два каталога или каталог с текущим окружением.
+// Код выхода как у diff: 0 — различий нет, 1 — есть, 2 — ошибка.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	lax := fs.Bool("lax", false, "trim quotes and spaces around values and skip invalid names")
+	raw := fs.Bool("raw", false, "compare values as written, without expanding ${VAR} references and @file: secrets")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [flags] <env-dir> [<env-dir>]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "With one directory shows how it changes the current environment.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return 2
+	}
+
+	live := os.Environ()
+	read := func(dir string) (Environment, error) {
+		env, err := ReadDirWithOptions(dir, ReadOptions{Lax: *lax})
+		if err != nil || *raw {
+			return env, err
+		}
+		return Expand(env, nil, live)
+	}
+
+	from, err := read(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading env: %v\n", err)
+		return 2
+	}
+
+	var to Environment
+	if fs.NArg() == 2 {
+		if to, err = read(fs.Arg(1)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading env: %v\n", err)
+			return 2
+		}
+	} else {
+		// Сравниваем текущее окружение с тем, которое получит команда
+		to = applyEnv(environment(live), from)
+		from = environment(live)
+	}
+
+	changes := DiffEnv(from, to)
+	for _, change := range changes {
+		for _, line := range change.Lines() {
+			fmt.Println(line)
+		}
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Change — различие одной переменной между двумя окружениями.
+// nil в Old или New означает, что переменной нет в соответствующем окружении.
+type Change struct {
+	Name string
+	Old  *EnvValue
+	New  *EnvValue
+}
+
+// Lines представляет изменение строками в стиле diff:
+//
+//	-NAME=old
+//	+NAME=new
+//	+unset NAME
+func (c Change) Lines() []string {
+	var lines []string
+	if c.Old != nil {
+		lines = append(lines, "-"+formatEnvValue(c.Name, *c.Old))
+	}
+	if c.New != nil {
+		lines = append(lines, "+"+formatEnvValue(c.Name, *c.New))
+	}
+	return lines
+}
+
+func formatEnvValue(name string, value EnvValue) string {
+	if value.NeedRemove {
+		return "unset " + name
+	}
+	return formatDotenv(name, value.Value)
+}
+
+// DiffEnv сравнивает окружения и возвращает изменения, отсортированные по имени.
+func DiffEnv(from, to Environment) []Change {
+	var changes []Change
+	for name, old := range from {
+		if value, ok := to[name]; !ok {
+			changes = append(changes, Change{Name: name, Old: &old})
+		} else if value != old {
+			changes = append(changes, Change{Name: name, Old: &old, New: &value})
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, Change{Name: name, New: &value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// environment превращает список KEY=VALUE в Environment.
+func environment(environ []string) Environment {
+	env := make(Environment, len(environ))
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = EnvValue{Value: value}
+		}
+	}
+	return env
+}
+
+// applyEnv возвращает окружение, которое получит команда, запущенная с env поверх base.
+func applyEnv(base, env Environment) Environment {
+	result := make(Environment, len(base)+len(env))
+	for name, value := range base {
+		result[name] = value
+	}
+	for name, value := range env {
+		if value.NeedRemove {
+			delete(result, name)
+		} else {
+			result[name] = EnvValue{Value: value.Value}
+		}
+	}
+	return result
+}
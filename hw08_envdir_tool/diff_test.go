@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffEnv(t *testing.T) {
+	from := Environment{
+		"SAME":    {Value: "x"},
+		"CHANGED": {Value: "old"},
+		"REMOVED": {Value: "gone"},
+		"UNSET":   {Value: "x"},
+	}
+	to := Environment{
+		"SAME":    {Value: "x"},
+		"CHANGED": {Value: "new value"},
+		"ADDED":   {Value: "a\nb"},
+		"UNSET":   {NeedRemove: true},
+	}
+
+	var lines []string
+	for _, change := range DiffEnv(from, to) {
+		lines = append(lines, change.Lines()...)
+	}
+	require.Equal(t, []string{
+		`+ADDED="a\nb"`,
+		"-CHANGED=old",
+		`+CHANGED="new value"`,
+		"-REMOVED=gone",
+		"-UNSET=x",
+		"+unset UNSET",
+	}, lines)
+
+	require.Empty(t, DiffEnv(from, from))
+}
+
+func TestApplyEnv(t *testing.T) {
+	base := environment([]string{"HOME=/home/user", "PATH=/bin", "EMPTY=", "INVALID"})
+	require.Equal(t, Environment{
+		"HOME":  {Value: "/home/user"},
+		"PATH":  {Value: "/bin"},
+		"EMPTY": {Value: ""},
+	}, base)
+
+	result := applyEnv(base, Environment{
+		"PATH":  {Value: "/usr/bin"},
+		"HOME":  {NeedRemove: true},
+		"NEW":   {Value: "x", Literal: true},
+		"OTHER": {NeedRemove: true},
+	})
+	require.Equal(t, Environment{
+		"PATH":  {Value: "/usr/bin"},
+		"EMPTY": {Value: ""},
+		"NEW":   {Value: "x"},
+	}, result)
+	require.Equal(t, "/home/user", base["HOME"].Value, "base is not modified")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var ErrDotenvSyntax = errors.New("dotenv syntax error")
+
+var (
+	validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	plainText = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]+$`)
+)
+
+// ReadDotenv читает переменные из файла в формате dotenv.
+func ReadDotenv(path string) (Environment, error) {
+	env, _, err := readDotenvTemplates(path)
+	return env, err
+}
+
+// readDotenvTemplates работает как ReadDotenv и возвращает также шаблоны для Expand.
+func readDotenvTemplates(path string) (Environment, Templates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	env, templates, err := parseDotenv(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return env, templates, nil
+}
+
+// parseDotenv разбирает строки вида [export] NAME=value. Значение может быть
+// без кавычек (тогда " #" начинает комментарий), в одинарных кавычках (как есть)
+// или в двойных (с escape-последовательностями \n, \t, \r, \", \\, \$).
+// Значения в одинарных кавычках не раскрываются Expand, а \$ даёт символ $,
+// который Expand тоже не раскрывает: для таких значений возвращается шаблон.
+// Значения в кавычках могут занимать несколько строк.
+func parseDotenv(r io.Reader) (Environment, Templates, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := make(Environment)
+	templates := make(Templates)
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		name, rest, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validName.MatchString(name) {
+			return nil, nil, fmt.Errorf("line %d: expected NAME=value: %w", lineNo, ErrDotenvSyntax)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		delete(templates, name) // Повторное присваивание заменяет и шаблон
+
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			env[name] = EnvValue{Value: stripComment(rest)}
+			continue
+		}
+
+		quote, body := rest[0], rest[1:]
+		end := closingQuote(body, quote)
+		for end < 0 {
+			// Значение продолжается на следующей строке
+			i++
+			if i >= len(lines) {
+				return nil, nil, fmt.Errorf("line %d: unterminated quoted value: %w", lineNo, ErrDotenvSyntax)
+			}
+			body += "\n" + lines[i]
+			end = closingQuote(body, quote)
+		}
+
+		if tail := strings.TrimSpace(body[end+1:]); tail != "" && tail[0] != '#' {
+			return nil, nil, fmt.Errorf("line %d: unexpected text after quoted value: %w", lineNo, ErrDotenvSyntax)
+		}
+		if quote == '"' {
+			value := unescape(body[:end], "$")
+			env[name] = EnvValue{Value: value}
+			if template := unescape(body[:end], "$$"); template != value {
+				templates[name] = template
+			}
+		} else {
+			env[name] = EnvValue{Value: body[:end], Literal: true}
+		}
+	}
+	return env, templates, nil
+}
+
+// stripComment удаляет комментарий и пробелы вокруг значения без кавычек.
+func stripComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// closingQuote ищет закрывающую кавычку. В двойных кавычках учитывается экранирование.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape раскрывает escape-последовательности двойных кавычек,
+// заменяя \$ на dollar.
+func unescape(s, dollar string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case '$':
+			b.WriteString(dollar)
+		default:
+			// Неизвестную последовательность оставляем как есть
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// formatDotenv записывает переменную строкой dotenv, которую читает parseDotenv.
+func formatDotenv(name, value string) string {
+	if plainText.MatchString(value) {
+		return name + "=" + value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return name + `="` + r.Replace(value) + `"`
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `
+# comment
+PLAIN=value
+export EXPORTED = spaced value  # trailing comment
+HASH=a#b
+EMPTY=
+SINGLE='literal \n $HOME'
+DOUBLE="tab\there \"quoted\" \$HOME"
+MULTI="first
+second" # comment
+export=reserved
+CRLF=windows` + "\r\n"
+
+	env, templates, err := parseDotenv(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"PLAIN":    {Value: "value"},
+		"EXPORTED": {Value: "spaced value"},
+		"HASH":     {Value: "a#b"},
+		"EMPTY":    {Value: ""},
+		"SINGLE":   {Value: `literal \n $HOME`, Literal: true},
+		"DOUBLE":   {Value: "tab\there \"quoted\" $HOME"},
+		"MULTI":    {Value: "first\nsecond"},
+		"export":   {Value: "reserved"},
+		"CRLF":     {Value: "windows"},
+	}, env)
+	require.Equal(t, Templates{"DOUBLE": "tab\there \"quoted\" $$HOME"}, templates)
+
+	// \$ остаётся символом $ и при раскрытии ссылок
+	expanded, err := Expand(env, templates, []string{"HOME=/root"})
+	require.NoError(t, err)
+	require.Equal(t, "tab\there \"quoted\" $HOME", expanded["DOUBLE"].Value)
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing equals", input: "NAME"},
+		{name: "invalid name", input: "1NAME=value"},
+		{name: "name with dash", input: "MY-NAME=value"},
+		{name: "unterminated quote", input: "A=\"value\nB=c"},
+		{name: "text after quote", input: "A='value' tail"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := parseDotenv(strings.NewReader(tc.input))
+			require.ErrorIs(t, err, ErrDotenvSyntax)
+			require.ErrorContains(t, err, "line 1")
+		})
+	}
+}
+
+func TestReadDotenv(t *testing.T) {
+	env, err := ReadDotenv("testdata/app.env")
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"HELLO": {Value: "world"},
+		"BAR":   {Value: "single $quoted", Literal: true},
+		"ADDED": {Value: "multi\nline"},
+	}, env)
+
+	_, err = ReadDotenv(filepath.Join(t.TempDir(), "missing.env"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFormatDotenv(t *testing.T) {
+	values := []string{"plain", "", "with space", "a\nb\tc\r", `"quoted" \ $HOME ${HOME}`, "#"}
+	for _, value := range values {
+		line := formatDotenv("NAME", value)
+		env, templates, err := parseDotenv(strings.NewReader(line))
+		require.NoError(t, err, line)
+		env, err = Expand(env, templates, nil)
+		require.NoError(t, err, line)
+		require.Equal(t, value, env["NAME"].Value, line)
+	}
+	require.Equal(t, "NAME=plain", formatDotenv("NAME", "plain"))
+}
@@ -1,24 +1,50 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+var ErrInvalidName = errors.New("invalid variable name")
+
 type Environment map[string]EnvValue
 
 // EnvValue helps to distinguish between empty files and files with the first empty line.
 type EnvValue struct {
 	Value      string
 	NeedRemove bool
+	// Literal — значение не раскрывается Expand (одинарные кавычки в dotenv).
+	Literal bool
+}
+
+// Templates — значения в синтаксисе Expand для переменных, у которых он отличается
+// от Value: \$ из dotenv хранится в Value как $, а в шаблоне как $$.
+type Templates map[string]string
+
+// ReadOptions задаёт правила чтения каталога.
+type ReadOptions struct {
+	// Lax — прежний нестрогий режим: кавычки и пробелы по краям значения удаляются,
+	// файлы с некорректными именами молча пропускаются.
+	Lax bool
 }
 
 // ReadDir reads a specified directory and returns map of env variables.
 // Variables represented as files where filename is name of variable, file first line is a value.
 func ReadDir(dir string) (Environment, error) {
+	return ReadDirWithOptions(dir, ReadOptions{})
+}
+
+// ReadDirWithOptions читает каталог как envdir из daemontools: от первой строки
+// файла отрезаются пробелы и табуляции в конце, нули заменяются переводами строки,
+// пустой файл означает удаление переменной. Скрытые файлы пропускаются,
+// а имя с "=" считается ошибкой.
+func ReadDirWithOptions(dir string, opts ReadOptions) (Environment, error) {
 	env := make(Environment)
 
 	entries, err := os.ReadDir(dir)
@@ -27,43 +53,50 @@ func ReadDir(dir string) (Environment, error) {
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() || strings.Contains(entry.Name(), "=") {
+		name := entry.Name()
+		if entry.IsDir() || (!opts.Lax && strings.HasPrefix(name, ".")) {
 			continue
 		}
-
-		filePath := filepath.Join(dir, entry.Name())
-		info, err := entry.Info()
-		if err != nil {
-			return nil, err
-		}
-
-		if info.Size() == 0 {
-			env[entry.Name()] = EnvValue{NeedRemove: true}
-			continue
+		if strings.Contains(name, "=") {
+			if opts.Lax {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, name), ErrInvalidName)
 		}
 
-		file, err := os.Open(filePath)
+		value, err := readValue(filepath.Join(dir, name), opts)
 		if err != nil {
 			return nil, err
 		}
-		defer file.Close()
+		env[name] = value
+	}
 
-		var buf bytes.Buffer
-		_, err = io.CopyN(&buf, file, info.Size())
-		if err != nil && err != io.EOF {
-			return nil, err
-		}
+	return env, nil
+}
 
-		firstLine := bytes.SplitN(buf.Bytes(), []byte{'\n'}, 2)[0]
-		value := bytes.ReplaceAll(firstLine, []byte{0}, []byte{'\n'})
-		valueStr := strings.TrimRight(string(value), " \t")
-		valueStr = strings.Trim(valueStr, `"`) // Удаляем кавычки если есть
+// readValue читает значение переменной из первой строки файла.
+func readValue(path string, opts ReadOptions) (EnvValue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return EnvValue{}, err
+	}
+	defer file.Close()
 
-		env[entry.Name()] = EnvValue{
-			Value:      strings.TrimSpace(valueStr), // Удаляем все пробелы по краям
-			NeedRemove: false,
-		}
+	firstLine, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return EnvValue{}, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(firstLine) == 0 {
+		return EnvValue{NeedRemove: true}, nil
 	}
 
-	return env, nil
+	firstLine = bytes.TrimSuffix(firstLine, []byte{'\n'})
+	value := strings.TrimRight(string(firstLine), " \t")
+	value = strings.ReplaceAll(value, "\x00", "\n")
+
+	if opts.Lax {
+		value = strings.Trim(value, `"`) // Удаляем кавычки если есть
+		value = strings.TrimSpace(value) // Удаляем все пробелы по краям
+	}
+	return EnvValue{Value: value}, nil
 }
@@ -1,7 +1,85 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeEnvDir создаёт каталог с файлами переменных.
+func makeEnvDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
 
 func TestReadDir(t *testing.T) {
-	// Place your code here
+	t.Run("testdata", func(t *testing.T) {
+		env, err := ReadDir("testdata/env")
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"BAR":   {Value: "bar"},
+			"EMPTY": {Value: ""},
+			"FOO":   {Value: "   foo\nwith new line"},
+			"HELLO": {Value: `"hello"`},
+			"UNSET": {NeedRemove: true},
+		}, env)
+	})
+
+	t.Run("trims only trailing spaces and tabs", func(t *testing.T) {
+		dir := makeEnvDir(t, map[string]string{
+			"A": " \tvalue \t \nsecond line",
+			"B": "value\r",
+			"C": "\n",
+		})
+		env, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"A": {Value: " \tvalue"},
+			"B": {Value: "value\r"},
+			"C": {Value: ""},
+		}, env)
+	})
+
+	t.Run("skips hidden files and directories", func(t *testing.T) {
+		dir := makeEnvDir(t, map[string]string{".hidden": "x", "A": "a"})
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "SUB"), 0o700))
+
+		env, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, Environment{"A": {Value: "a"}}, env)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		dir := makeEnvDir(t, map[string]string{"A=B": "x"})
+		_, err := ReadDir(dir)
+		require.ErrorIs(t, err, ErrInvalidName)
+		require.ErrorContains(t, err, "A=B")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := ReadDir(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestReadDirLax(t *testing.T) {
+	dir := makeEnvDir(t, map[string]string{
+		"A":   `"quoted"  `,
+		"B":   "  spaced\t",
+		"A=B": "x",
+		"C":   "",
+	})
+	env, err := ReadDirWithOptions(dir, ReadOptions{Lax: true})
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"A": {Value: "quoted"},
+		"B": {Value: "spaced"},
+		"C": {NeedRemove: true},
+	}, env)
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnrepresentable = errors.New("value can't be stored in envdir")
+
+// WriteOptions задаёт, как записывается каталог.
+type WriteOptions struct {
+	// Prune — удалить из каталога файлы переменных, которых нет в env.
+	Prune bool
+}
+
+// WriteDir записывает env в каталог так, чтобы ReadDir прочитал его обратно:
+// по файлу на переменную, переводы строки кодируются нулями, переменная
+// с NeedRemove записывается пустым файлом. Значения записываются как есть,
+// так что каталог читает и envdir из daemontools. Каждый файл заменяется атомарно.
+func WriteDir(dir string, env Environment, opts WriteOptions) error {
+	for name, value := range env {
+		if err := checkWritable(name, value); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, value := range env {
+		if err := writeValue(dir, name, value); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Prune {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if _, ok := env[name]; ok || entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWritable проверяет, что переменная переживёт запись и чтение ReadDir.
+func checkWritable(name string, value EnvValue) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, "=/\x00") {
+		return fmt.Errorf("%q: %w", name, ErrInvalidName)
+	}
+	if value.NeedRemove {
+		return nil
+	}
+	if strings.ContainsRune(value.Value, 0) {
+		return fmt.Errorf("%s: NUL character: %w", name, ErrUnrepresentable)
+	}
+	if strings.TrimRight(value.Value, " \t") != value.Value {
+		return fmt.Errorf("%s: trailing spaces: %w", name, ErrUnrepresentable)
+	}
+	return nil
+}
+
+// writeValue записывает файл переменной через временный файл.
+func writeValue(dir, name string, value EnvValue) error {
+	var content string
+	if !value.NeedRemove {
+		// Перевод строки в конце отличает пустое значение от удаления переменной
+		content = strings.ReplaceAll(value.Value, "\n", "\x00") + "\n"
+	}
+
+	// Скрытые файлы ReadDir пропускает, поэтому недописанный файл не будет прочитан
+	tmp, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
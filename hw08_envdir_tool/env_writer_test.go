@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDir(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		env := Environment{
+			"PLAIN":   {Value: "value"},
+			"EMPTY":   {Value: ""},
+			"MULTI":   {Value: "first\nsecond\n"},
+			"LEADING": {Value: "  spaced"},
+			"REMOVE":  {NeedRemove: true},
+		}
+		dir := filepath.Join(t.TempDir(), "env")
+		require.NoError(t, WriteDir(dir, env, WriteOptions{}))
+
+		actual, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, env, actual)
+
+		data, err := os.ReadFile(filepath.Join(dir, "MULTI"))
+		require.NoError(t, err)
+		require.Equal(t, "first\x00second\x00\n", string(data))
+	})
+
+	t.Run("values are written as is", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, WriteDir(dir, Environment{
+			"LITERAL": {Value: "${HOME} costs $$5", Literal: true},
+		}, WriteOptions{}))
+
+		data, err := os.ReadFile(filepath.Join(dir, "LITERAL"))
+		require.NoError(t, err)
+		require.Equal(t, "${HOME} costs $$5\n", string(data))
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		dir := makeEnvDir(t, map[string]string{"OLD": "x", "KEEP": "x", ".hidden": "x"})
+
+		require.NoError(t, WriteDir(dir, Environment{"KEEP": {Value: "new"}}, WriteOptions{Prune: true}))
+		env, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, Environment{"KEEP": {Value: "new"}}, env)
+		require.FileExists(t, filepath.Join(dir, ".hidden"))
+
+		// Без Prune остальные файлы не трогаются
+		require.NoError(t, WriteDir(dir, Environment{"NEW": {Value: "x"}}, WriteOptions{}))
+		env, err = ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, env, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			env  Environment
+			err  error
+		}{
+			{name: "name with equals", env: Environment{"A=B": {Value: "x"}}, err: ErrInvalidName},
+			{name: "hidden name", env: Environment{".A": {Value: "x"}}, err: ErrInvalidName},
+			{name: "name with slash", env: Environment{"../A": {Value: "x"}}, err: ErrInvalidName},
+			{name: "trailing spaces", env: Environment{"A": {Value: "x \t"}}, err: ErrUnrepresentable},
+			{name: "NUL", env: Environment{"A": {Value: "x\x00y"}}, err: ErrUnrepresentable},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				dir := filepath.Join(t.TempDir(), "env")
+				err := WriteDir(dir, tc.env, WriteOptions{})
+				require.ErrorIs(t, err, tc.err)
+				require.NoDirExists(t, dir, "nothing is written on error")
+			})
+		}
+	})
+}
@@ -1,41 +1,235 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 )
 
+// Задержки перед перезапуском и остановка команды по умолчанию.
+const (
+	defaultBackoff     = time.Second
+	defaultMaxBackoff  = 30 * time.Second
+	defaultStopSignal  = syscall.SIGTERM
+	defaultGracePeriod = 10 * time.Second
+)
+
+// forwardedSignals пересылаются запущенной команде.
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT,
+	syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// RunOptions задаёт режим запуска команды.
+type RunOptions struct {
+	// Clean — не наследовать окружение текущего процесса.
+	Clean bool
+	// Exec — заменить текущий процесс командой вместо запуска дочернего.
+	Exec bool
+	// Restart — перезапускать команду, пока она завершается с ошибкой.
+	Restart bool
+	// MaxRestarts — предельное число перезапусков, 0 — без ограничения.
+	MaxRestarts int
+	// Backoff — задержка перед первым перезапуском, дальше удваивается до MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// StopSignal — сигнал, которым утилита сама останавливает команду (например,
+	// для перезапуска в режиме Watch). Если за GracePeriod команда не завершилась,
+	// она убивается SIGKILL.
+	StopSignal  syscall.Signal
+	GracePeriod time.Duration
+}
+
 // RunCmd runs a command + arguments (cmd) with environment variables from env.
 func RunCmd(cmd []string, env Environment) int {
+	return RunCmdWithOptions(cmd, env, RunOptions{})
+}
+
+// RunCmdWithOptions запускает команду с окружением, собранным по opts.
+// Сигналы, полученные утилитой, пересылаются команде. Если команда завершилась
+// по сигналу, возвращается 128+номер сигнала, как в shell.
+func RunCmdWithOptions(cmd []string, env Environment, opts RunOptions) int {
 	if len(cmd) == 0 {
 		return 1
 	}
+	environ := prepareEnv(env, baseEnv(opts))
+
+	if opts.Exec {
+		return execCmd(cmd, environ)
+	}
 
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	if opts.Restart {
+		return supervise(cmd, environ, sigs, opts)
+	}
+	code, _ := runOnce(cmd, environ, sigs, nil, opts)
+	return code
+}
+
+// execCmd заменяет текущий процесс командой. Возвращает управление только при ошибке.
+func execCmd(cmd []string, environ []string) int {
+	path, err := exec.LookPath(cmd[0])
+	if err == nil {
+		err = syscall.Exec(path, cmd, environ)
+	}
+	fmt.Fprintf(os.Stderr, "Error executing command: %v\n", err)
+	return startErrorCode(err)
+}
+
+// runOnce запускает команду и пересылает ей сигналы до её завершения.
+// Когда закрывается stop, команда останавливается по opts.StopSignal и opts.GracePeriod.
+// stopped — получала ли утилита сигнал завершения.
+func runOnce(cmd, environ []string, sigs <-chan os.Signal, stop <-chan struct{}, opts RunOptions) (int, bool) {
 	command := exec.Command(cmd[0], cmd[1:]...)
 	command.Stdin = os.Stdin
 	command.Stdout = os.Stdout
 	command.Stderr = os.Stderr
-	command.Env = prepareEnv(env)
+	command.Env = environ
+
+	if err := command.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing command: %v\n", err)
+		return startErrorCode(err), false
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- command.Wait()
+	}()
+
+	stopSignal := opts.StopSignal
+	if stopSignal == 0 {
+		stopSignal = defaultStopSignal
+	}
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = defaultGracePeriod
+	}
+
+	var stopped bool
+	var kill <-chan time.Time
+	for {
+		select {
+		case sig := <-sigs:
+			stopped = stopped || isTermination(sig)
+			// Процесс мог уже завершиться, это не ошибка
+			_ = command.Process.Signal(sig)
+		case <-stop:
+			stop = nil
+			_ = command.Process.Signal(stopSignal)
+			kill = time.After(grace)
+		case <-kill:
+			_ = command.Process.Kill()
+		case err := <-waited:
+			return exitCode(err), stopped
+		}
+	}
+}
+
+// supervise перезапускает команду с растущей задержкой, пока она не завершится
+// успешно, не исчерпает MaxRestarts или утилита не получит сигнал завершения.
+func supervise(cmd []string, environ []string, sigs <-chan os.Signal, opts RunOptions) int {
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	delay := backoff
+	for restarts := 0; ; restarts++ {
+		started := time.Now()
+		code, stopped := runOnce(cmd, environ, sigs, nil, opts)
+		if code == 0 || stopped || (opts.MaxRestarts > 0 && restarts >= opts.MaxRestarts) {
+			return code
+		}
 
-	if err := command.Run(); err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				return status.ExitStatus()
+		// После долгой стабильной работы начинаем с минимальной задержки
+		if time.Since(started) > maxBackoff {
+			delay = backoff
+		}
+		fmt.Fprintf(os.Stderr, "Command exited with code %d, restarting in %s\n", code, delay)
+		if !sleep(delay, sigs) {
+			return code
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// sleep ждёт d и возвращает false, если за это время пришёл сигнал завершения.
+func sleep(d time.Duration, sigs <-chan os.Signal) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case sig := <-sigs:
+			if isTermination(sig) {
+				return false
 			}
+		case <-timer.C:
+			return true
 		}
+	}
+}
+
+func isTermination(sig os.Signal) bool {
+	return sig == syscall.SIGINT || sig == syscall.SIGTERM || sig == syscall.SIGQUIT
+}
+
+// exitCode переводит результат Wait в код выхода.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
+			if status.Signaled() {
+				return 128 + int(status.Signal())
+			}
+			return status.ExitStatus()
+		}
+	}
+	return 1
+}
+
+// startErrorCode переводит ошибку запуска в код выхода, как в shell:
+// 127 — команда не найдена, 126 — её нельзя выполнить.
+func startErrorCode(err error) int {
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return 127
+	case errors.Is(err, fs.ErrPermission):
+		return 126
+	default:
 		return 1
 	}
+}
 
-	return 0
+// baseEnv возвращает наследуемое окружение.
+func baseEnv(opts RunOptions) []string {
+	if opts.Clean {
+		return nil
+	}
+	return os.Environ()
 }
 
-func prepareEnv(env Environment) []string {
+// prepareEnv дополняет base переменными из env.
+func prepareEnv(env Environment, base []string) []string {
 	var result []string
 
 	// Сначала добавляем существующие переменные, кроме тех, что нужно удалить
-	for _, e := range os.Environ() {
+	for _, e := range base {
 		pair := strings.SplitN(e, "=", 2)
 		if len(pair) != 2 {
 			continue
@@ -45,9 +239,9 @@ func prepareEnv(env Environment) []string {
 		}
 	}
 
-	// Затем добавляем новые переменные
+	// Затем добавляем новые переменные, в том числе с пустым значением
 	for name, value := range env {
-		if !value.NeedRemove && value.Value != "" {
+		if !value.NeedRemove {
 			result = append(result, name+"="+value.Value)
 		}
 	}
@@ -1,7 +1,135 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
 
 func TestRunCmd(t *testing.T) {
-	// Place your code here
+	t.Run("exit code", func(t *testing.T) {
+		require.Equal(t, 0, RunCmd([]string{"true"}, nil))
+		require.Equal(t, 3, RunCmd([]string{"sh", "-c", "exit 3"}, nil))
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		require.Equal(t, 1, RunCmd(nil, nil))
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		require.Equal(t, 127, RunCmd([]string{"go-envdir-no-such-command"}, nil))
+		require.Equal(t, 127, RunCmd([]string{filepath.Join(t.TempDir(), "missing")}, nil))
+	})
+
+	t.Run("command not executable", func(t *testing.T) {
+		script := filepath.Join(t.TempDir(), "script.sh")
+		require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o644))
+		require.Equal(t, 126, RunCmd([]string{script}, nil))
+	})
+
+	t.Run("killed by signal", func(t *testing.T) {
+		require.Equal(t, 128+int(syscall.SIGTERM), RunCmd([]string{"sh", "-c", "kill -TERM $$"}, nil))
+	})
+
+	t.Run("forwards signals", func(t *testing.T) {
+		ready := filepath.Join(t.TempDir(), "ready")
+		script := `trap 'kill $!; exit 42' TERM; touch "$READY"; sleep 10 & wait`
+
+		go func() {
+			// Сигнал можно слать только когда команда запущена и подписка на сигналы активна
+			for {
+				if _, err := os.Stat(ready); err == nil {
+					syscall.Kill(os.Getpid(), syscall.SIGTERM)
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		code := RunCmd([]string{"sh", "-c", script}, Environment{"READY": {Value: ready}})
+		require.Equal(t, 42, code)
+	})
+}
+
+func TestRunCmdRestart(t *testing.T) {
+	opts := RunOptions{Restart: true, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	// Каждый запуск увеличивает счётчик в файле и успешен только на третий раз
+	script := `n=$(cat "$COUNTER" 2>/dev/null || echo 0); n=$((n+1)); echo $n > "$COUNTER"; [ $n -ge "$SUCCESS_AT" ]`
+
+	runs := func(t *testing.T, counter string) string {
+		t.Helper()
+		data, err := os.ReadFile(counter)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(data))
+	}
+
+	t.Run("until success", func(t *testing.T) {
+		counter := filepath.Join(t.TempDir(), "counter")
+		env := Environment{"COUNTER": {Value: counter}, "SUCCESS_AT": {Value: "3"}}
+		require.Equal(t, 0, RunCmdWithOptions([]string{"sh", "-c", script}, env, opts))
+		require.Equal(t, "3", runs(t, counter))
+	})
+
+	t.Run("max restarts", func(t *testing.T) {
+		counter := filepath.Join(t.TempDir(), "counter")
+		env := Environment{"COUNTER": {Value: counter}, "SUCCESS_AT": {Value: "100"}}
+		opts := opts
+		opts.MaxRestarts = 2
+		require.Equal(t, 1, RunCmdWithOptions([]string{"sh", "-c", script}, env, opts))
+		require.Equal(t, "3", runs(t, counter))
+	})
+}
+
+func TestRunCmdExec(t *testing.T) {
+	if os.Getenv("GO_ENVDIR_EXEC_HELPER") == "1" {
+		// Процесс теста заменяется командой, код ниже не выполнится
+		os.Exit(RunCmdWithOptions([]string{"sh", "-c", `echo "$$ $VALUE"`}, Environment{"VALUE": {Value: "replaced"}},
+			RunOptions{Exec: true}))
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunCmdExec$")
+	cmd.Env = append(os.Environ(), "GO_ENVDIR_EXEC_HELPER=1")
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%d replaced\n", cmd.Process.Pid), string(out))
+}
+
+func TestExitCode(t *testing.T) {
+	require.Equal(t, 0, exitCode(nil))
+	require.Equal(t, 1, exitCode(errors.New("start failed")))
+}
+
+func TestStartErrorCode(t *testing.T) {
+	require.Equal(t, 127, startErrorCode(&exec.Error{Name: "cmd", Err: exec.ErrNotFound}))
+	require.Equal(t, 126, startErrorCode(&os.PathError{Op: "fork/exec", Path: "cmd", Err: syscall.EACCES}))
+	require.Equal(t, 1, startErrorCode(errors.New("start failed")))
+}
+
+func TestPrepareEnv(t *testing.T) {
+	t.Setenv("KEEP", "keep")
+	t.Setenv("REPLACE", "old")
+	t.Setenv("REMOVE", "remove")
+
+	result := prepareEnv(Environment{
+		"REPLACE": {Value: "new"},
+		"REMOVE":  {NeedRemove: true},
+		"EMPTY":   {Value: ""},
+	}, os.Environ())
+	require.Contains(t, result, "KEEP=keep")
+	require.Contains(t, result, "REPLACE=new")
+	require.Contains(t, result, "EMPTY=")
+	require.NotContains(t, result, "REPLACE=old")
+	require.NotContains(t, result, "REMOVE=remove")
+
+	// Без базового окружения остаются только переменные из env
+	result = prepareEnv(Environment{"A": {Value: "a"}, "B": {NeedRemove: true}}, nil)
+	require.Equal(t, []string{"A=a"}, result)
 }
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrReferenceCycle = errors.New("variable reference cycle")
+	ErrBadReference   = errors.New("invalid variable reference")
+)
+
+// secretPrefix — значение вида @file:/path заменяется содержимым файла.
+const secretPrefix = "@file:"
+
+// Expand подставляет в значения env ссылки на другие переменные:
+// ${NAME} и ${NAME:-default} (default — если переменная не задана или пуста),
+// $$ — символ $. Ссылки ищутся сначала в env, затем в base (KEY=VALUE).
+// Ссылка переменной на саму себя (PATH=${PATH}:/bin) берёт значение из base.
+// Значение @file:/path заменяется содержимым файла без завершающего перевода строки;
+// в пути ссылки подставляются, в содержимом — нет.
+// Если для переменной есть шаблон в templates, раскрывается он, а не Value.
+func Expand(env Environment, templates Templates, base []string) (Environment, error) {
+	e := &expander{
+		env:       env,
+		templates: templates,
+		base:      make(map[string]string, len(base)),
+		resolved:  make(map[string]string, len(env)),
+	}
+	for _, kv := range base {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			e.base[name] = value
+		}
+	}
+
+	result := make(Environment, len(env))
+	for name, value := range env {
+		if value.NeedRemove {
+			result[name] = value
+			continue
+		}
+		expanded, _, err := e.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = EnvValue{Value: expanded}
+	}
+	return result, nil
+}
+
+type expander struct {
+	env       Environment
+	templates Templates
+	base      map[string]string
+	resolved  map[string]string
+	stack     []string // Переменные, которые сейчас раскрываются
+}
+
+// resolve возвращает раскрытое значение переменной и признак того, что она задана.
+func (e *expander) resolve(name string) (string, bool, error) {
+	if v, ok := e.resolved[name]; ok {
+		return v, true, nil
+	}
+	value, ok := e.env[name]
+	if !ok {
+		v, ok := e.base[name]
+		return v, ok, nil
+	}
+	if value.NeedRemove {
+		return "", false, nil
+	}
+
+	for i, n := range e.stack {
+		if n == name {
+			chain := strings.Join(append(e.stack[i:], name), " -> ")
+			return "", false, fmt.Errorf("%s: %w", chain, ErrReferenceCycle)
+		}
+	}
+	e.stack = append(e.stack, name)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+
+	v, err := e.expandValue(name, value)
+	if err != nil {
+		return "", false, err
+	}
+	e.resolved[name] = v
+	return v, true, nil
+}
+
+func (e *expander) expandValue(name string, value EnvValue) (string, error) {
+	if value.Literal {
+		return value.Value, nil
+	}
+
+	template, ok := e.templates[name]
+	if !ok {
+		template = value.Value
+	}
+	path, secret := strings.CutPrefix(template, secretPrefix)
+	if !secret {
+		return e.interpolate(name, template)
+	}
+
+	path, err := e.interpolate(name, path)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: read secret: %w", name, err)
+	}
+	s := strings.TrimSuffix(string(content), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// interpolate подставляет ссылки в значение переменной self.
+func (e *expander) interpolate(self, s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%s: unterminated %q: %w", self, s[i:], ErrBadReference)
+			}
+			v, err := e.reference(self, s[i+2:end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// reference раскрывает выражение NAME или NAME:-default внутри ${}.
+func (e *expander) reference(self, expr string) (string, error) {
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("%s: ${%s}: %w", self, expr, ErrBadReference)
+	}
+
+	var v string
+	var ok bool
+	if name == self {
+		v, ok = e.base[name]
+	} else {
+		var err error
+		if v, ok, err = e.resolve(name); err != nil {
+			return "", err
+		}
+	}
+
+	if hasDefault && (!ok || v == "") {
+		return e.interpolate(self, def)
+	}
+	return v, nil
+}
+
+// closingBrace ищет "}", закрывающую ссылку, с учётом вложенных ${} в default.
+func closingBrace(s string, from int) int {
+	depth := 0
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	base := []string{"HOME=/home/user", "PATH=/usr/bin", "BLANK="}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "plain", value: "value", expected: "value"},
+		{name: "reference", value: "${HOST}:${PORT}", expected: "localhost:8080"},
+		{name: "nested reference", value: "http://${ADDR}/", expected: "http://localhost:8080/"},
+		{name: "base environment", value: "${HOME}/app", expected: "/home/user/app"},
+		{name: "unset", value: "[${MISSING}]", expected: "[]"},
+		{name: "removed", value: "[${REMOVED}]", expected: "[]"},
+		{name: "default for unset", value: "${MISSING:-fallback}", expected: "fallback"},
+		{name: "default for empty", value: "${BLANK:-fallback}", expected: "fallback"},
+		{name: "default not used", value: "${HOST:-fallback}", expected: "localhost"},
+		{name: "default with reference", value: "${MISSING:-${HOME}/x}", expected: "/home/user/x"},
+		{name: "escaped dollar", value: "$${HOME} costs $5 $", expected: "${HOME} costs $5 $"},
+		{name: "literal", value: "${HOME}", expected: "${HOME}"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := Environment{
+				"HOST":    {Value: "localhost"},
+				"PORT":    {Value: "8080"},
+				"ADDR":    {Value: "${HOST}:${PORT}"},
+				"REMOVED": {NeedRemove: true},
+				"HOME":    {Value: "${HOME}"},
+				"VALUE":   {Value: tc.value, Literal: tc.name == "literal"},
+			}
+			expanded, err := Expand(env, nil, base)
+			require.NoError(t, err)
+			require.Equal(t, EnvValue{Value: tc.expected}, expanded["VALUE"])
+			require.Equal(t, EnvValue{NeedRemove: true}, expanded["REMOVED"])
+		})
+	}
+
+	t.Run("self reference uses base", func(t *testing.T) {
+		env := Environment{"PATH": {Value: "${PATH}:/opt/bin"}}
+		expanded, err := Expand(env, nil, base)
+		require.NoError(t, err)
+		require.Equal(t, "/usr/bin:/opt/bin", expanded["PATH"].Value)
+	})
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      Environment
+		err      error
+		contains string
+	}{
+		{
+			name:     "cycle",
+			env:      Environment{"A": {Value: "${B}"}, "B": {Value: "x${C}"}, "C": {Value: "${A}"}},
+			err:      ErrReferenceCycle,
+			contains: " -> ",
+		},
+		{
+			name:     "cycle through default",
+			env:      Environment{"A": {Value: "${MISSING:-${B}}"}, "B": {Value: "${A}"}},
+			err:      ErrReferenceCycle,
+			contains: " -> ",
+		},
+		{
+			name:     "unterminated",
+			env:      Environment{"A": {Value: "${B"}},
+			err:      ErrBadReference,
+			contains: "A",
+		},
+		{
+			name:     "invalid name",
+			env:      Environment{"A": {Value: "${B-C}"}},
+			err:      ErrBadReference,
+			contains: "${B-C}",
+		},
+		{
+			name:     "missing secret",
+			env:      Environment{"A": {Value: secretPrefix + "/nonexistent/secret"}},
+			err:      os.ErrNotExist,
+			contains: "A",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Expand(tc.env, nil, nil)
+			require.ErrorIs(t, err, tc.err)
+			require.ErrorContains(t, err, tc.contains)
+		})
+	}
+}
+
+func TestExpandSecrets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db"), []byte("p@ss ${NOT_EXPANDED}\n"), 0o600))
+
+	env := Environment{
+		"SECRETS":  {Value: dir},
+		"PASSWORD": {Value: secretPrefix + "${SECRETS}/db"},
+		"DSN":      {Value: "postgres://user:${PASSWORD}@db"},
+		"LITERAL":  {Value: secretPrefix + "${SECRETS}/db", Literal: true},
+	}
+	expanded, err := Expand(env, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "p@ss ${NOT_EXPANDED}", expanded["PASSWORD"].Value)
+	require.Equal(t, "postgres://user:p@ss ${NOT_EXPANDED}@db", expanded["DSN"].Value)
+	require.Equal(t, secretPrefix+"${SECRETS}/db", expanded["LITERAL"].Value)
+}
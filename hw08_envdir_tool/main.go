@@ -1,24 +1,174 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
+var (
+	lax        bool
+	clean      bool
+	printEnv   bool
+	raw        bool
+	sources    []Source
+	execMode   bool
+	restart    bool
+	maxRestart int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	watch      bool
+	stopSignal string
+	grace      time.Duration
+	poll       time.Duration
+)
+
+// sourceFlag добавляет источник в общий список, сохраняя порядок флагов -d и -f.
+type sourceFlag struct {
+	kind SourceKind
+}
+
+func (f sourceFlag) String() string {
+	return ""
+}
+
+func (f sourceFlag) Set(path string) error {
+	sources = append(sources, Source{Kind: f.kind, Path: path})
+	return nil
+}
+
+func init() {
+	flag.BoolVar(&lax, "lax", false, "trim quotes and spaces around values and skip invalid names")
+	flag.Var(sourceFlag{kind: SourceDir}, "d", "envdir `directory`, can be repeated")
+	flag.Var(sourceFlag{kind: SourceDotenv}, "f", "dotenv `file`, can be repeated")
+	flag.BoolVar(&clean, "i", false, "start with an empty environment")
+	flag.BoolVar(&printEnv, "print", false, "print the resulting environment instead of running a command")
+	flag.BoolVar(&raw, "raw", false, "don't expand ${VAR} references and @file: secrets in values")
+	flag.BoolVar(&execMode, "exec", false, "replace go-envdir process with the command")
+	flag.BoolVar(&restart, "restart", false, "restart the command while it exits with non-zero code")
+	flag.IntVar(&maxRestart, "max-restarts", 0, "maximum number of restarts, 0 for unlimited")
+	flag.DurationVar(&backoff, "backoff", defaultBackoff, "delay before the first restart, doubled on each failure")
+	flag.DurationVar(&maxBackoff, "max-backoff", defaultMaxBackoff, "maximum delay between restarts")
+	flag.BoolVar(&watch, "watch", false, "restart the command when env sources change")
+	flag.StringVar(&stopSignal, "stop-signal", "TERM", "signal to stop the command before restart in watch mode")
+	flag.DurationVar(&grace, "grace", defaultGracePeriod, "time to wait after stop signal before killing the command")
+	flag.DurationVar(&poll, "poll", 0, "poll env sources with this interval instead of inotify")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <env-dir> <cmd> [args...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] -d <env-dir> [-d <env-dir>] [-f <file.env>] <cmd> [args...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s export [flags] <env-dir>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s diff [flags] <env-dir> [<env-dir>]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <env-dir> <cmd> [args...]\n", os.Args[0])
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
+	flag.Parse()
+	args := flag.Args()
+
+	// Без -d и -f первый аргумент — каталог, как в envdir
+	if len(sources) == 0 && len(args) > 0 {
+		sources = append(sources, Source{Kind: SourceDir, Path: args[0]})
+		args = args[1:]
+	}
+	if !printEnv && (len(sources) == 0 || len(args) == 0) {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if execMode && (restart || watch) {
+		fmt.Fprintln(os.Stderr, "-exec can't be used with -restart or -watch")
+		os.Exit(1)
+	}
+	if restart && watch {
+		fmt.Fprintln(os.Stderr, "-restart and -watch can't be used together")
+		os.Exit(1)
+	}
+
+	sig, err := parseSignal(stopSignal)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	opts := RunOptions{
+		Clean:       clean,
+		Exec:        execMode,
+		Restart:     restart,
+		MaxRestarts: maxRestart,
+		Backoff:     backoff,
+		MaxBackoff:  maxBackoff,
+		StopSignal:  sig,
+		GracePeriod: grace,
+	}
+	load := func() (Environment, error) {
+		return loadEnv(opts)
+	}
 
-	envDir := os.Args[1]
-	cmd := os.Args[2:]
+	if watch && !printEnv {
+		os.Exit(Watch(args, sources, load, opts, WatchOptions{PollInterval: poll}))
+	}
 
-	env, err := ReadDir(envDir)
+	env, err := load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading env dir: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error reading env: %v\n", err)
 		os.Exit(1)
 	}
 
-	os.Exit(RunCmd(cmd, env))
+	if printEnv {
+		printEnvironment(prepareEnv(env, baseEnv(opts)))
+		return
+	}
+
+	os.Exit(RunCmdWithOptions(args, env, opts))
+}
+
+// loadEnv читает источники из флагов и раскрывает ссылки, если не задан -raw.
+func loadEnv(opts RunOptions) (Environment, error) {
+	env, templates, err := LoadEnv(sources, ReadOptions{Lax: lax})
+	if err != nil || raw {
+		return env, err
+	}
+	return Expand(env, templates, baseEnv(opts))
+}
+
+// signalNames — сигналы, которые можно передать в -stop-signal по имени.
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+}
+
+// parseSignal разбирает сигнал по имени (TERM, SIGTERM) или номеру.
+func parseSignal(s string) (syscall.Signal, error) {
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	if sig, ok := signalNames[name]; ok {
+		return sig, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", s)
+}
+
+// printEnvironment выводит переменные в формате dotenv, отсортированными по имени.
+func printEnvironment(vars []string) {
+	sort.Strings(vars)
+	for _, v := range vars {
+		name, value, _ := strings.Cut(v, "=")
+		fmt.Println(formatDotenv(name, value))
+	}
 }
@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// SourceKind — формат источника переменных.
+type SourceKind int
+
+const (
+	SourceDir    SourceKind = iota // Каталог envdir
+	SourceDotenv                   // Файл в формате dotenv
+)
+
+// Source — один источник переменных окружения.
+type Source struct {
+	Kind SourceKind
+	Path string
+}
+
+func (s Source) String() string {
+	if s.Kind == SourceDotenv {
+		return "-f " + s.Path
+	}
+	return "-d " + s.Path
+}
+
+// LoadEnv читает источники по порядку и объединяет их: переменная из более
+// позднего источника заменяет (или удаляет) одноимённую из предыдущих.
+// Шаблоны для Expand объединяются так же.
+func LoadEnv(sources []Source, opts ReadOptions) (Environment, Templates, error) {
+	env := make(Environment)
+	templates := make(Templates)
+	for _, src := range sources {
+		var layer Environment
+		var layerTemplates Templates
+		var err error
+		switch src.Kind {
+		case SourceDir:
+			layer, err = ReadDirWithOptions(src.Path, opts)
+		case SourceDotenv:
+			layer, layerTemplates, err = readDotenvTemplates(src.Path)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", src, err)
+		}
+
+		for name, value := range layer {
+			env[name] = value
+			if template, ok := layerTemplates[name]; ok {
+				templates[name] = template
+			} else {
+				delete(templates, name)
+			}
+		}
+	}
+	return env, templates, nil
+}
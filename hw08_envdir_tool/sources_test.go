@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEnv(t *testing.T) {
+	t.Run("later sources win", func(t *testing.T) {
+		override := makeEnvDir(t, map[string]string{"HELLO": "override", "BAR": ""})
+		env, _, err := LoadEnv([]Source{
+			{Kind: SourceDir, Path: "testdata/env"},
+			{Kind: SourceDotenv, Path: "testdata/app.env"},
+			{Kind: SourceDir, Path: override},
+		}, ReadOptions{})
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"HELLO": {Value: "override"},
+			"BAR":   {NeedRemove: true},
+			"FOO":   {Value: "   foo\nwith new line"},
+			"EMPTY": {Value: ""},
+			"UNSET": {NeedRemove: true},
+			"ADDED": {Value: "multi\nline"},
+		}, env)
+	})
+
+	t.Run("directories are expanded", func(t *testing.T) {
+		secret := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+		dir := makeEnvDir(t, map[string]string{"PASSWORD": "pa$$word", "TOKEN": "@file:" + secret})
+
+		env, templates, err := LoadEnv([]Source{{Kind: SourceDir, Path: dir}}, ReadOptions{})
+		require.NoError(t, err)
+		env, err = Expand(env, templates, nil)
+		require.NoError(t, err)
+		require.Equal(t, "pa$word", env["PASSWORD"].Value)
+		require.Equal(t, "s3cret", env["TOKEN"].Value)
+	})
+
+	t.Run("templates follow overrides", func(t *testing.T) {
+		dotenv := filepath.Join(t.TempDir(), "app.env")
+		require.NoError(t, os.WriteFile(dotenv, []byte(`PRICE="\$5"`+"\n"+`COST="\$7"`), 0o600))
+		dir := makeEnvDir(t, map[string]string{"PRICE": "${COST}"})
+
+		env, templates, err := LoadEnv([]Source{
+			{Kind: SourceDotenv, Path: dotenv},
+			{Kind: SourceDir, Path: dir},
+		}, ReadOptions{})
+		require.NoError(t, err)
+		require.Equal(t, Templates{"COST": "$$7"}, templates)
+
+		env, err = Expand(env, templates, nil)
+		require.NoError(t, err)
+		require.Equal(t, "$7", env["PRICE"].Value)
+	})
+
+	t.Run("options apply to directories", func(t *testing.T) {
+		env, _, err := LoadEnv([]Source{{Kind: SourceDir, Path: "testdata/env"}}, ReadOptions{Lax: true})
+		require.NoError(t, err)
+		require.Equal(t, "hello", env["HELLO"].Value)
+	})
+
+	t.Run("error names the source", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.env")
+		_, _, err := LoadEnv([]Source{{Kind: SourceDotenv, Path: missing}}, ReadOptions{})
+		require.ErrorIs(t, err, os.ErrNotExist)
+		require.ErrorContains(t, err, "-f "+missing)
+	})
+
+	t.Run("no sources", func(t *testing.T) {
+		env, _, err := LoadEnv(nil, ReadOptions{})
+		require.NoError(t, err)
+		require.Empty(t, env)
+	})
+}
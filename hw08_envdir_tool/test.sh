@@ -21,5 +21,81 @@ arguments are arg1=1 arg2=2'
 
 [ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
 
+result=$(./go-envdir -exec "$(pwd)/testdata/env" "/bin/bash" "$(pwd)/testdata/echo.sh" arg1=1 arg2=2)
+[ "${result}" = "${expected}" ] || (echo -e "invalid output in exec mode: ${result}" && exit 1)
+
+code=0
+./go-envdir "$(pwd)/testdata/env" /bin/sh -c 'kill -TERM $$' || code=$?
+[ "${code}" -eq 143 ] || (echo "invalid exit code for signaled command: ${code}" && exit 1)
+
+result=$(./go-envdir -d "$(pwd)/testdata/env" -f "$(pwd)/testdata/app.env" "/bin/bash" "$(pwd)/testdata/echo.sh" arg1=1)
+expected='HELLO is (world)
+BAR is (single $quoted)
+FOO is (   foo
+with new line)
+UNSET is ()
+ADDED is (multi
+line)
+EMPTY is ()
+arguments are arg1=1'
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir -i --print -d "$(pwd)/testdata/env" -f "$(pwd)/testdata/app.env")
+expected='ADDED="multi\nline"
+BAR="single \$quoted"
+EMPTY=""
+FOO="   foo\nwith new line"
+HELLO=world'
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+tmp=$(mktemp -d)
+printf 's3cret\n' > "${tmp}/password"
+cat > "${tmp}/app.env" <<ENV
+SECRETS=${tmp}
+PASSWORD=@file:\${SECRETS}/password
+HELLO=\${USER_NAME:-guest}:\${PASSWORD}
+BAR='\${PASSWORD}'
+ENV
+
+result=$(./go-envdir -i --print -f "${tmp}/app.env")
+expected="BAR=\"\\\${PASSWORD}\"
+HELLO=guest:s3cret
+PASSWORD=s3cret
+SECRETS=${tmp}"
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+rm -rf "${tmp}"
+
+tmp=$(mktemp -d)
+./go-envdir export -f "$(pwd)/testdata/app.env" -unset UNSET "${tmp}/env"
+code=0
+result=$(./go-envdir diff "$(pwd)/testdata/env" "${tmp}/env") || code=$?
+expected='-BAR=bar
++BAR="single \$quoted"
++ADDED="multi\nline"
+-EMPTY=""
+-FOO="   foo\nwith new line"
+-HELLO="\"hello\""
++HELLO=world'
+
+[ "${code}" -eq 1 ] || (echo "invalid diff exit code: ${code}" && exit 1)
+[ "$(echo "${result}" | sort)" = "$(echo "${expected}" | sort)" ] || (echo -e "invalid diff: ${result}" && exit 1)
+
+APP_NAME='cost $5' APP_EMPTY= ./go-envdir export -prefix APP_ "${tmp}/app"
+APP_NAME='cost $5' APP_EMPTY= ./go-envdir diff "${tmp}/app"
+
+warning=$(APP_PADDED='padded ' ./go-envdir export -prefix APP_ "${tmp}/padded" 2>&1)
+[ ! -e "${tmp}/padded/APP_PADDED" ] || (echo "APP_PADDED should be skipped" && exit 1)
+[ "${warning}" = "Skipping APP_PADDED: trailing spaces: value can't be stored in envdir" ] \
+  || (echo -e "invalid warning: ${warning}" && exit 1)
+
+printf 'PADDED="padded "\n' > "${tmp}/padded.env"
+if ./go-envdir export -f "${tmp}/padded.env" "${tmp}/padded" 2>/dev/null; then
+  echo "export of unrepresentable dotenv value should fail" && exit 1
+fi
+rm -rf "${tmp}"
+
 rm -f go-envdir
 echo "PASS"
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Параметры отслеживания изменений по умолчанию.
+const (
+	defaultPollInterval = time.Second
+	defaultDebounce     = 200 * time.Millisecond
+)
+
+// WatchOptions задаёт, как отслеживаются изменения источников.
+type WatchOptions struct {
+	// PollInterval — если больше нуля, каталоги опрашиваются с этим интервалом
+	// вместо inotify. Опрос используется и тогда, когда inotify недоступен.
+	PollInterval time.Duration
+	// Debounce — сколько ждать после изменения, прежде чем перечитывать окружение:
+	// файлы обычно меняются пачкой.
+	Debounce time.Duration
+}
+
+// watcher сообщает об изменениях в отслеживаемых каталогах.
+type watcher interface {
+	Events() <-chan struct{}
+	Close() error
+}
+
+// runResult — итог одного запуска команды.
+type runResult struct {
+	code    int
+	stopped bool
+}
+
+// Watch запускает команду и перезапускает её, когда меняются каталоги и файлы
+// из sources и окружение, полученное load, становится другим. Старая команда
+// останавливается по opts.StopSignal и opts.GracePeriod. Watch завершается,
+// когда команда завершилась сама, и возвращает её код выхода.
+func Watch(cmd []string, sources []Source, load func() (Environment, error), opts RunOptions, wopts WatchOptions) int {
+	if len(cmd) == 0 {
+		return 1
+	}
+	env, err := load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading env: %v\n", err)
+		return 1
+	}
+
+	w := newWatcher(watchPaths(sources), wopts)
+	defer w.Close()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	debounce := wopts.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	for {
+		stop := make(chan struct{})
+		exited := make(chan runResult, 1)
+		environ := prepareEnv(env, baseEnv(opts))
+		go func() {
+			code, stopped := runOnce(cmd, environ, sigs, stop, opts)
+			exited <- runResult{code: code, stopped: stopped}
+		}()
+
+		next, r, changed := waitChange(env, load, w.Events(), exited, debounce)
+		if !changed {
+			return r.code
+		}
+
+		fmt.Fprintln(os.Stderr, "Environment changed, restarting command")
+		close(stop)
+		if r := <-exited; r.stopped {
+			return r.code
+		}
+		env = next
+	}
+}
+
+// waitChange ждёт, пока команда завершится (возвращается её результат)
+// или изменится окружение (возвращается новое окружение и true).
+func waitChange(env Environment, load func() (Environment, error), events <-chan struct{},
+	exited <-chan runResult, debounce time.Duration,
+) (Environment, runResult, bool) {
+	var settle <-chan time.Time
+	for {
+		select {
+		case r := <-exited:
+			return nil, r, false
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			settle = time.After(debounce)
+		case <-settle:
+			settle = nil
+			next, err := load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reloading env, keeping previous: %v\n", err)
+				continue
+			}
+			if !maps.Equal(env, next) {
+				return next, runResult{}, true
+			}
+		}
+	}
+}
+
+// watchPaths возвращает каталоги, изменения в которых нужно отслеживать.
+// Для dotenv-файла это его каталог: редакторы часто заменяют файл целиком.
+func watchPaths(sources []Source) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, src := range sources {
+		path := src.Path
+		if src.Kind == SourceDotenv {
+			path = filepath.Dir(path)
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// newWatcher отслеживает paths через inotify, а если он недоступен или
+// задан PollInterval — периодическим опросом. На опрос inotify переходит
+// и сам, если отслеживаемый каталог удалили.
+func newWatcher(paths []string, opts WatchOptions) watcher {
+	if opts.PollInterval <= 0 {
+		w, err := newNotifyWatcher(paths, defaultPollInterval)
+		if err == nil {
+			return w
+		}
+		opts.PollInterval = defaultPollInterval
+	}
+	return newPollWatcher(paths, opts.PollInterval)
+}
+
+// pollWatcher сравнивает содержимое каталогов с интервалом.
+type pollWatcher struct {
+	events chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newPollWatcher(paths []string, interval time.Duration) *pollWatcher {
+	w := &pollWatcher{events: make(chan struct{}, 1), done: make(chan struct{})}
+	// Начальное состояние снимаем сразу, чтобы не пропустить изменения после создания
+	prev := snapshot(paths)
+	go func() {
+		defer close(w.events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				if cur := snapshot(paths); cur != prev {
+					prev = cur
+					notify(w.events)
+				}
+			}
+		}
+	}()
+	return w
+}
+
+func (w *pollWatcher) Events() <-chan struct{} {
+	return w.events
+}
+
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+// snapshot описывает имена, размеры и время изменения файлов в каталогах.
+func snapshot(paths []string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", path, err)
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%s %d %d %s\n", filepath.Join(path, entry.Name()),
+				info.Size(), info.ModTime().UnixNano(), info.Mode())
+		}
+	}
+	return b.String()
+}
+
+// notify отправляет событие, не блокируясь, если предыдущее ещё не прочитано.
+func notify(events chan struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher получает изменения каталогов от ядра. Любое событие означает,
+// что окружение нужно перечитать. Если отслеживаемый каталог удалили или
+// заменили (mv new env), наблюдение ставится заново на тот же путь, а если
+// каталога по пути нет — watcher переходит на опрос с интервалом fallback.
+type inotifyWatcher struct {
+	file     *os.File
+	paths    []string
+	watches  map[int32][]string // Дескриптор наблюдения -> каталоги
+	fallback time.Duration
+	events   chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newNotifyWatcher(paths []string, fallback time.Duration) (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	watches := make(map[int32][]string, len(paths))
+	for _, path := range paths {
+		wd, err := syscall.InotifyAddWatch(fd, path, inotifyMask)
+		if err != nil {
+			syscall.Close(fd)
+			return nil, fmt.Errorf("watch %s: %w", path, err)
+		}
+		watches[int32(wd)] = append(watches[int32(wd)], path)
+	}
+
+	// Неблокирующий дескриптор обслуживается планировщиком Go, и Close прерывает Read
+	w := &inotifyWatcher{
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		paths:    paths,
+		watches:  watches,
+		fallback: fallback,
+		events:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go func() {
+		defer close(w.events)
+		buf := make([]byte, 64<<10)
+		for {
+			n, err := w.file.Read(buf)
+			if err != nil {
+				return
+			}
+			notify(w.events)
+			if !w.rewatch(buf[:n]) {
+				w.file.Close()
+				w.poll()
+				return
+			}
+		}
+	}()
+	return w, nil
+}
+
+// rewatch ставит наблюдение заново для каталогов, которые удалили или
+// переместили. Возвращает false, если какого-то каталога по пути больше нет.
+func (w *inotifyWatcher) rewatch(buf []byte) bool {
+	var lost []int32
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		wd := int32(binary.NativeEndian.Uint32(buf[0:]))
+		mask := binary.NativeEndian.Uint32(buf[4:])
+		size := syscall.SizeofInotifyEvent + int(binary.NativeEndian.Uint32(buf[12:]))
+		buf = buf[min(size, len(buf)):]
+
+		if _, ok := w.watches[wd]; ok && mask&(syscall.IN_DELETE_SELF|syscall.IN_MOVE_SELF) != 0 {
+			lost = append(lost, wd)
+		}
+	}
+	if len(lost) == 0 {
+		return true
+	}
+
+	conn, err := w.file.SyscallConn()
+	if err != nil {
+		return false
+	}
+	ok := true
+	// Control не даёт Close освободить дескриптор, пока идут вызовы
+	err = conn.Control(func(fd uintptr) {
+		for _, wd := range lost {
+			paths := w.watches[wd]
+			// После перемещения наблюдение осталось на старом inode, снимаем его
+			delete(w.watches, wd)
+			syscall.InotifyRmWatch(int(fd), uint32(wd))
+			for _, path := range paths {
+				newWd, err := syscall.InotifyAddWatch(int(fd), path, inotifyMask)
+				if err != nil {
+					ok = false
+					return
+				}
+				w.watches[int32(newWd)] = append(w.watches[int32(newWd)], path)
+			}
+		}
+	})
+	return err == nil && ok
+}
+
+// poll пересылает события опроса всех каталогов до закрытия w.
+func (w *inotifyWatcher) poll() {
+	p := newPollWatcher(w.paths, w.fallback)
+	defer p.Close()
+	for {
+		select {
+		case <-w.done:
+			return
+		case _, ok := <-p.Events():
+			if !ok {
+				return
+			}
+			notify(w.events)
+		}
+	}
+}
+
+func (w *inotifyWatcher) Events() <-chan struct{} {
+	return w.events
+}
+
+func (w *inotifyWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	if err := w.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+func newNotifyWatcher([]string, time.Duration) (watcher, error) {
+	return nil, errors.ErrUnsupported
+}
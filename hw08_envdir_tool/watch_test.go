@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchers(t *testing.T) {
+	watchers := map[string]func(paths []string) (watcher, error){
+		"inotify": func(paths []string) (watcher, error) {
+			return newNotifyWatcher(paths, 10*time.Millisecond)
+		},
+		"poll": func(paths []string) (watcher, error) {
+			return newPollWatcher(paths, 10*time.Millisecond), nil
+		},
+	}
+
+	for name, newFn := range watchers {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := newFn([]string{dir})
+			if errors.Is(err, errors.ErrUnsupported) {
+				t.Skip("not supported on this platform")
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "VALUE"), []byte("x"), 0o600))
+			select {
+			case <-w.Events():
+			case <-time.After(2 * time.Second):
+				require.Fail(t, "no event after file change")
+			}
+
+			require.NoError(t, w.Close())
+			require.Eventually(t, func() bool {
+				_, ok := <-w.Events()
+				return !ok
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+// waitEvent ждёт событие от w, пропустив события, пришедшие до вызова.
+func waitEvent(t *testing.T, w watcher, change func()) {
+	t.Helper()
+	for quiet := false; !quiet; {
+		select {
+		case <-w.Events():
+		case <-time.After(50 * time.Millisecond):
+			quiet = true
+		}
+	}
+	change()
+	select {
+	case _, ok := <-w.Events():
+		require.True(t, ok, "watcher closed")
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "no event after change")
+	}
+}
+
+func TestNotifyWatcherReplacedDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "env")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	w, err := newNotifyWatcher([]string{dir}, 10*time.Millisecond)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("not supported on this platform")
+	}
+	require.NoError(t, err)
+	defer w.Close()
+
+	write := func(value string) func() {
+		return func() {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "VALUE"), []byte(value), 0o600))
+		}
+	}
+
+	// Деплой заменяет каталог целиком
+	waitEvent(t, w, func() {
+		require.NoError(t, os.Mkdir(filepath.Join(root, "new"), 0o755))
+		require.NoError(t, os.Rename(dir, filepath.Join(root, "old")))
+		require.NoError(t, os.Rename(filepath.Join(root, "new"), dir))
+	})
+	waitEvent(t, w, write("moved"))
+
+	// Каталог удалён: наблюдение продолжается опросом
+	waitEvent(t, w, func() { require.NoError(t, os.RemoveAll(dir)) })
+	waitEvent(t, w, func() { require.NoError(t, os.Mkdir(dir, 0o755)) })
+	waitEvent(t, w, write("recreated"))
+
+	require.NoError(t, w.Close())
+	require.Eventually(t, func() bool {
+		_, ok := <-w.Events()
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWatchPaths(t *testing.T) {
+	paths := watchPaths([]Source{
+		{Kind: SourceDir, Path: "env"},
+		{Kind: SourceDotenv, Path: "conf/app.env"},
+		{Kind: SourceDotenv, Path: "env/.env"},
+	})
+	require.Equal(t, []string{"env", "conf"}, paths)
+}
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		opts   RunOptions
+		wopts  WatchOptions
+	}{
+		{
+			name:   "inotify",
+			script: `trap 'kill $!; exit 0' TERM; sleep 10 & wait`,
+		},
+		{
+			name:   "poll with custom stop signal",
+			script: `trap 'kill $!; exit 0' USR1; sleep 10 & wait`,
+			opts:   RunOptions{StopSignal: syscall.SIGUSR1},
+			wopts:  WatchOptions{PollInterval: 10 * time.Millisecond},
+		},
+		{
+			name:   "killed after grace period",
+			script: `trap '' TERM; while :; do sleep 0.05; done`,
+			opts:   RunOptions{GracePeriod: 100 * time.Millisecond},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := makeEnvDir(t, map[string]string{"VALUE": "first"})
+			out := filepath.Join(t.TempDir(), "out")
+			sources := []Source{{Kind: SourceDir, Path: dir}}
+			load := func() (Environment, error) {
+				env, _, err := LoadEnv(sources, ReadOptions{})
+				if err == nil {
+					env["OUT"] = EnvValue{Value: out}
+				}
+				return env, err
+			}
+			// Второй запуск завершается сам, и Watch возвращает его код
+			script := `echo "$VALUE" >> "$OUT"; [ "$VALUE" = second ] && exit 7; ` + tc.script
+
+			go func() {
+				for {
+					if data, _ := os.ReadFile(out); len(data) > 0 {
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				// Изменение, не меняющее окружение, не перезапускает команду
+				os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0o600)
+				time.Sleep(100 * time.Millisecond)
+				os.WriteFile(filepath.Join(dir, "VALUE"), []byte("second"), 0o600)
+			}()
+
+			tc.wopts.Debounce = 20 * time.Millisecond
+			code := Watch([]string{"sh", "-c", script}, sources, load, tc.opts, tc.wopts)
+			require.Equal(t, 7, code)
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			require.Equal(t, []string{"first", "second"}, strings.Fields(string(data)))
+		})
+	}
+
+	t.Run("invalid env", func(t *testing.T) {
+		load := func() (Environment, error) {
+			return nil, ErrInvalidName
+		}
+		require.Equal(t, 1, Watch([]string{"true"}, nil, load, RunOptions{}, WatchOptions{}))
+	})
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const runtimePath = "github.com/fixme_my_friend/hw09_struct_validator"
+
+type generator struct {
+	pkg     *packageInfo
+	targets map[string]bool
+	body    bytes.Buffer
+	vars    bytes.Buffer // скомпилированные regexp
+	regexps map[string]string
+	imports map[string]bool
+	depth   int // вложенность циклов: имена переменных i0, p1...
+}
+
+// generate создаёт файл с методами Validate для структур names или, если
+// список пуст, для всех структур пакета с тэгами validate.
+func generate(pkg *packageInfo, names []string) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		targets: make(map[string]bool),
+		imports: map[string]bool{runtimePath: true},
+		regexps: make(map[string]string),
+	}
+	if len(names) == 0 {
+		for _, name := range pkg.order {
+			if hasTags(pkg.structs[name]) {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		if _, ok := pkg.structs[name]; !ok {
+			return nil, fmt.Errorf("%s: struct type not found in package %s", name, pkg.name)
+		}
+		g.targets[name] = true
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("package %s: no structs with validate tags", pkg.name)
+	}
+
+	for _, name := range names {
+		if err := g.structType(pkg.structs[name]); err != nil {
+			return nil, err
+		}
+	}
+	return g.file()
+}
+
+func hasTags(s *structInfo) bool {
+	for _, f := range s.fields {
+		if f.tag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) file() ([]byte, error) {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by validgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.name)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		if path != runtimePath {
+			imports = append(imports, path)
+		}
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, "\nvalidator %q\n)\n\n", runtimePath)
+	out.Write(g.vars.Bytes())
+	out.Write(g.body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func (g *generator) structType(s *structInfo) error {
+	g.printf(`
+// Validate проверяет %[1]s по тэгам validate без рефлексии.
+func (x %[1]s) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (%[1]s) GeneratedValidator() {}
+
+func (x *%[1]s) validateTags(prefix string, errs *validator.ValidationErrors) {
+`, s.name)
+	for _, f := range s.fields {
+		if f.tag == "" {
+			continue
+		}
+		if err := g.field(s, f); err != nil {
+			return fmt.Errorf("%s.%s: %w", s.name, f.name, err)
+		}
+	}
+	g.printf("}\n")
+	return nil
+}
+
+func (g *generator) field(s *structInfo, f fieldInfo) error {
+	rules, err := parseRules(f.tag, f.typ.leaf())
+	if err != nil {
+		return err
+	}
+	if rules.nested && !g.targets[f.typ.leaf().name] {
+		return fmt.Errorf("nested type %s is not generated, add it to -type", f.typ.leaf().name)
+	}
+
+	expr := "x." + f.name
+	path := "prefix + " + strconv.Quote(f.name)
+	inline := f.embedded && rules.nested
+	if inline {
+		path = `strings.TrimSuffix(prefix, ".")`
+	}
+
+	// Проверки значения и сравнения полей выполняются, только если required прошло
+	outer := g.body
+	g.body = bytes.Buffer{}
+	if rules.required && f.typ.kind == kindPointer {
+		g.value("*"+expr, path, f.typ.elem, rules, inline)
+	} else {
+		g.value(expr, path, f.typ, rules, inline)
+	}
+	for _, ref := range rules.refs {
+		if err := g.ref(s, f, ref, path); err != nil {
+			return err
+		}
+	}
+	checks := g.body
+	g.body = outer
+
+	if rules.required {
+		g.printf("if %s {\n", zeroCheck(expr, f.typ))
+		g.appendErr(path, "validator.ErrRequired")
+		if checks.Len() > 0 {
+			g.printf("} else {\n")
+		}
+	}
+	g.body.Write(checks.Bytes())
+	if rules.required {
+		g.printf("}\n")
+	}
+	return nil
+}
+
+func (g *generator) appendErr(path, err string) {
+	if strings.HasPrefix(path, "strings.") {
+		g.imports["strings"] = true
+	}
+	g.printf("*errs = append(*errs, validator.ValidationError{Field: %s, Err: %s})\n", path, err)
+}
+
+// joinLit дописывает к строковому выражению path литерал lit.
+func joinLit(path, lit string) string {
+	if strings.HasSuffix(path, `"`) {
+		return path[:len(path)-1] + lit + `"`
+	}
+	return path + ` + "` + lit + `"`
+}
+
+// operand заключает разыменование в скобки перед индексом или вызовом метода.
+func operand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+func zeroCheck(expr string, t *typeInfo) string {
+	if t.kind == kindPointer || t.kind == kindMap || (t.kind == kindSlice && !t.array) {
+		return expr + " == nil"
+	}
+	return "validator.IsZero(" + expr + ")"
+}
+
+// hasWork сообщает, есть ли что проверять в значениях типа t.
+func hasWork(t *typeInfo, rules *fieldRules) bool {
+	return len(rules.checks) > 0 || (rules.nested && t.leaf().kind == kindStruct)
+}
+
+// value спускается через указатели, слайсы и map, как validateValue.
+func (g *generator) value(expr, path string, t *typeInfo, rules *fieldRules, inline bool) {
+	if !hasWork(t, rules) {
+		return
+	}
+	d := g.depth
+	switch t.kind { //nolint:exhaustive // Остальные виды — проверяемые значения
+	case kindPointer:
+		g.printf("if %s != nil {\n", expr)
+		g.value("*"+expr, path, t.elem, rules, inline)
+		g.printf("}\n")
+	case kindSlice:
+		g.depth++
+		g.printf("for i%[1]d := range %[2]s {\n", d, expr)
+		g.printf("p%[1]d := %[2]s + strconv.Itoa(i%[1]d) + \"]\"\n", d, joinLit(path, "["))
+		g.imports["strconv"] = true
+		g.value(fmt.Sprintf("%s[i%d]", operand(expr), d), fmt.Sprintf("p%d", d), t.elem, rules, false)
+		g.printf("}\n")
+		g.depth--
+	case kindMap:
+		g.depth++
+		g.printf("for _, k%[1]d := range validator.SortedKeys(%[2]s) {\ne%[1]d := %[2]s[k%[1]d]\n", d, operand(expr))
+		g.printf("p%[1]d := %[2]s + fmt.Sprint(k%[1]d) + \"]\"\n", d, joinLit(path, "["))
+		g.imports["fmt"] = true
+		g.value(fmt.Sprintf("e%d", d), fmt.Sprintf("p%d", d), t.elem, rules, false)
+		g.printf("}\n")
+		g.depth--
+	case kindStruct:
+		prefix := joinLit(path, ".")
+		if inline {
+			prefix = "prefix"
+		}
+		g.printf("%s.validateTags(%s, errs)\n", operand(expr), prefix)
+	default:
+		for _, r := range rules.checks {
+			g.printf("if err := %s; err != nil {\n", g.check(expr, t, r))
+			g.appendErr(path, "err")
+			g.printf("}\n")
+		}
+	}
+}
+
+// check возвращает вызов проверки правила r для значения expr.
+func (g *generator) check(expr string, t *typeInfo, r rule) string {
+	switch {
+	case r.name == "len":
+		return fmt.Sprintf("validator.CheckLen(string(%s), %s)", expr, r.arg)
+	case r.name == "regexp":
+		return fmt.Sprintf("validator.CheckRegexp(string(%s), %s)", expr, g.regexpVar(r.arg))
+	case r.name == "in" && t.kind == kindString:
+		return fmt.Sprintf("validator.CheckStringIn(string(%s), %#v)", expr, strings.Split(r.arg, ","))
+	case r.name == "in":
+		values, _ := parseInts(r.arg)
+		return fmt.Sprintf("validator.CheckIntIn(int64(%s), %#v)", expr, values)
+	case r.name == "min":
+		n, _ := parseInt(r.arg)
+		return fmt.Sprintf("validator.CheckMin(int64(%s), %d)", expr, n)
+	default:
+		n, _ := parseInt(r.arg)
+		return fmt.Sprintf("validator.CheckMax(int64(%s), %d)", expr, n)
+	}
+}
+
+// regexpVar объявляет переменную с выражением, скомпилированным при загрузке пакета.
+func (g *generator) regexpVar(pattern string) string {
+	if name, ok := g.regexps[pattern]; ok {
+		return name
+	}
+	g.imports["regexp"] = true
+	name := fmt.Sprintf("validgenRegexp%d", len(g.regexps))
+	g.regexps[pattern] = name
+	fmt.Fprintf(&g.vars, "var %s = regexp.MustCompile(%q)\n", name, pattern)
+	return name
+}
+
+// ref генерирует межполевое правило, как fieldRef.check.
+func (g *generator) ref(s *structInfo, f fieldInfo, r rule, path string) error {
+	other, ok := s.field(r.arg, g.pkg)
+	switch {
+	case !ok:
+		return fmt.Errorf("rule %q: no exported field %q", r.name, r.arg)
+	case other.typ.src != f.typ.src:
+		return fmt.Errorf("rule %q: field %s is %s, not %s", r.name, r.arg, other.typ.src, f.typ.src)
+	}
+
+	a, b, t := "x."+f.name, "x."+r.arg, f.typ
+	if t.kind == kindPointer {
+		g.printf("if %s != nil && %s != nil {\n", a, b)
+		a, b, t = "*"+a, "*"+b, t.elem
+	}
+
+	var compare string
+	switch {
+	case t.kind == kindTime:
+		compare = fmt.Sprintf("%s.Compare(%s)", operand(a), b)
+	case t.ordered():
+		g.imports["cmp"] = true
+		compare = fmt.Sprintf("cmp.Compare(%s, %s)", a, b)
+	case r.name == "eqfield" || r.name == "nefield":
+		if t.kind == kindSlice || t.kind == kindMap {
+			return fmt.Errorf("rule %q is invalid for %s", r.name, f.typ.src)
+		}
+		compare = fmt.Sprintf("validator.CompareEqual(%s, %s)", a, b)
+	default:
+		return fmt.Errorf("rule %q is invalid for %s", r.name, f.typ.src)
+	}
+
+	g.printf("if err := validator.CheckField(%q, %s, %q); err != nil {\n", r.name, compare, r.arg)
+	g.appendErr(path, "err")
+	g.printf("}\n")
+	if f.typ.kind == kindPointer {
+		g.printf("}\n")
+	}
+	return nil
+}
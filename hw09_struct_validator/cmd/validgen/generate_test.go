@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFileIsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "models")
+	pkg, err := loadPackage(dir, defaultOutput)
+	require.NoError(t, err)
+
+	src, err := generate(pkg, nil)
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile(filepath.Join(dir, defaultOutput))
+	require.NoError(t, err)
+	require.Equal(t, string(committed), string(src), "run go generate ./internal/models")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		types []string
+		err   string
+	}{
+		{
+			name: "custom rule",
+			src:  "type T struct { Name string `validate:\"even\"` }",
+			err:  `T.Name: rule "even": not supported by validgen`,
+		},
+		{
+			name: "len on int",
+			src:  "type T struct { Age int `validate:\"len:2\"` }",
+			err:  `T.Age: rule "len:2": not applicable to int`,
+		},
+		{
+			name: "bad regexp",
+			src:  "type T struct { Name string `validate:\"regexp:[a-\"` }",
+			err:  "T.Name: rule \"regexp:[a-\": error parsing regexp: missing closing ]: `[a-`",
+		},
+		{
+			name: "nested on string",
+			src:  "type T struct { Name string `validate:\"nested\"` }",
+			err:  `T.Name: rule "nested" is invalid for string`,
+		},
+		{
+			name:  "nested type not generated",
+			src:   "type A struct { Zip string `validate:\"len:6\"` }\ntype T struct { A A `validate:\"nested\"` }",
+			types: []string{"T"},
+			err:   "T.A: nested type A is not generated, add it to -type",
+		},
+		{
+			name: "unknown field",
+			src:  "type T struct { Confirm string `validate:\"eqfield:Password\"` }",
+			err:  `T.Confirm: rule "eqfield": no exported field "Password"`,
+		},
+		{
+			name: "mismatched field",
+			src:  "type T struct { Start int\nEnd string `validate:\"gtfield:Start\"` }",
+			err:  `T.End: rule "gtfield": field Start is int, not string`,
+		},
+		{
+			name: "unordered field",
+			src:  "type T struct { A bool\nB bool `validate:\"gtfield:A\"` }",
+			err:  `T.B: rule "gtfield" is invalid for bool`,
+		},
+		{
+			name:  "unknown type",
+			src:   "type T struct{}",
+			types: []string{"User"},
+			err:   "User: struct type not found in package p",
+		},
+		{
+			name: "no tags",
+			src:  "type T struct{ Name string }",
+			err:  "package p: no structs with validate tags",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte("package p\n\n"+tc.src+"\n"), 0o600))
+
+			err := run(dir, defaultOutput, tc.types)
+			require.EqualError(t, err, tc.err)
+			require.NoFileExists(t, filepath.Join(dir, defaultOutput))
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\ntype Node struct {\n" +
+		"\tName string `validate:\"len:1\"`\n" +
+		"\tNext *Node `validate:\"nested\"`\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o600))
+
+	require.NoError(t, run(dir, "out_gen.go", nil))
+	out, err := os.ReadFile(filepath.Join(dir, "out_gen.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(out), "func (x Node) Validate() error")
+	require.Contains(t, string(out), `(*x.Next).validateTags(prefix+"Next.", errs)`)
+
+	// Созданный файл не мешает повторной генерации
+	require.NoError(t, run(dir, "out_gen.go", nil))
+}
+
+func TestRunSkipsUnexportedEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\ntype signature struct {\n" +
+		"\tAuthor string `validate:\"len:5\"`\n}\n\n" +
+		"type Letter struct {\n" +
+		"\tsignature `validate:\"nested\"`\n" +
+		"\tZip string `validate:\"len:6\"`\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o600))
+
+	require.NoError(t, run(dir, "out_gen.go", []string{"Letter"}))
+	out, err := os.ReadFile(filepath.Join(dir, "out_gen.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(out), "func (x Letter) Validate() error")
+	require.NotContains(t, string(out), "x.signature")
+}
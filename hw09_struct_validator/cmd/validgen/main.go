@@ -0,0 +1,57 @@
+// Команда validgen создаёт для структур пакета методы Validate, которые
+// проверяют тэги validate без рефлексии и возвращают те же ValidationErrors,
+// что и hw09structvalidator.Validate:
+//
+//	//go:generate go run github.com/fixme_my_friend/hw09_struct_validator/cmd/validgen -type User,Address
+//
+// Без -type методы создаются для всех структур пакета с тэгами validate.
+// Типы из директивы nested тоже должны быть в списке. Пользовательские
+// правила RegisterRule и Validatable генератор не поддерживает.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultOutput = "validate_gen.go"
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct types")
+	output := flag.String("output", defaultOutput, "output file name in the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: validgen [-type T1,T2] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	if err := run(dir, *output, names); err != nil {
+		fmt.Fprintln(os.Stderr, "validgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output string, names []string) error {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg, names)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644) //nolint:gosec // Исходный код, а не секрет
+}
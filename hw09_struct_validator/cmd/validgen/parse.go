@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var errUnsupported = errors.New("not supported by validgen")
+
+// kind — вид типа поля с точки зрения генератора.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindTime
+	kindStruct // именованная структура этого пакета
+	kindOther  // прочие типы: для них доступны только required, eqfield и nefield
+	kindPointer
+	kindSlice // слайс или массив
+	kindMap
+)
+
+type typeInfo struct {
+	kind  kind
+	name  string // имя структуры для kindStruct
+	elem  *typeInfo
+	array bool   // kindSlice — массив, его нельзя сравнить с nil
+	src   string // тип как в исходнике: сравниваемые поля должны совпадать по нему
+}
+
+// leaf снимает указатели, слайсы и map до типа проверяемых значений.
+func (t *typeInfo) leaf() *typeInfo {
+	for t.elem != nil {
+		t = t.elem
+	}
+	return t
+}
+
+func (t *typeInfo) ordered() bool {
+	switch t.kind { //nolint:exhaustive // Остальные типы не упорядочены
+	case kindString, kindInt, kindUint, kindFloat, kindTime:
+		return true
+	default:
+		return false
+	}
+}
+
+type fieldInfo struct {
+	name     string
+	embedded bool
+	typ      *typeInfo
+	tag      string // значение тэга validate
+}
+
+type structInfo struct {
+	name   string
+	fields []fieldInfo
+}
+
+// field ищет поле по имени, в том числе во встроенных структурах.
+func (s *structInfo) field(name string, pkg *packageInfo) (*fieldInfo, bool) {
+	for i := range s.fields {
+		if s.fields[i].name == name {
+			return &s.fields[i], true
+		}
+	}
+	for _, f := range s.fields {
+		if !f.embedded || f.typ.kind != kindStruct {
+			continue
+		}
+		if found, ok := pkg.structs[f.typ.name].field(name, pkg); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+type packageInfo struct {
+	name    string
+	decls   map[string]ast.Expr // объявления типов пакета
+	structs map[string]*structInfo
+	order   []string // структуры в порядке объявления
+}
+
+// loadPackage разбирает .go файлы каталога dir, кроме тестов и файла skip.
+func loadPackage(dir, skip string) (*packageInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &packageInfo{decls: make(map[string]ast.Expr), structs: make(map[string]*structInfo)}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == skip {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name != "" && pkg.name != file.Name.Name {
+			return nil, fmt.Errorf("%s: several packages: %s and %s", dir, pkg.name, file.Name.Name)
+		}
+		pkg.name = file.Name.Name
+		files = append(files, file)
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.TypeParams != nil {
+					continue
+				}
+				pkg.decls[ts.Name.Name] = ts.Type
+				if _, ok := ts.Type.(*ast.StructType); ok {
+					pkg.order = append(pkg.order, ts.Name.Name)
+				}
+			}
+		}
+	}
+
+	for _, name := range pkg.order {
+		s, err := pkg.parseStruct(name, pkg.decls[name].(*ast.StructType))
+		if err != nil {
+			return nil, err
+		}
+		pkg.structs[name] = s
+	}
+	return pkg, nil
+}
+
+func (p *packageInfo) parseStruct(name string, st *ast.StructType) (*structInfo, error) {
+	s := &structInfo{name: name}
+	for _, f := range st.Fields.List {
+		var tag string
+		if f.Tag != nil {
+			raw, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad tag %s: %w", name, f.Tag.Value, err)
+			}
+			tag = reflect.StructTag(raw).Get("validate")
+		}
+		typ := p.resolve(f.Type, 0)
+
+		if len(f.Names) == 0 {
+			// Встроенная структура неэкспортируемого типа Validate тоже не видна
+			if name := embeddedName(f.Type); ast.IsExported(name) {
+				s.fields = append(s.fields, fieldInfo{name: name, embedded: true, typ: typ, tag: tag})
+			}
+			continue
+		}
+		for _, n := range f.Names {
+			// Validate не видит неэкспортируемые поля
+			if n.IsExported() {
+				s.fields = append(s.fields, fieldInfo{name: n.Name, typ: typ, tag: tag})
+			}
+		}
+	}
+	return s, nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+var basicKinds = map[string]kind{
+	"string": kindString,
+	"int":    kindInt, "int8": kindInt, "int16": kindInt, "int32": kindInt, "int64": kindInt, "rune": kindInt,
+	"uint": kindUint, "uint8": kindUint, "uint16": kindUint, "uint32": kindUint, "uint64": kindUint,
+	"uintptr": kindUint, "byte": kindUint,
+	"float32": kindFloat, "float64": kindFloat,
+	"bool": kindBool,
+}
+
+// maxDepth ограничивает разбор определений вида type A B; type B A.
+const maxDepth = 32
+
+// resolve определяет вид типа по выражению из исходника.
+func (p *packageInfo) resolve(expr ast.Expr, depth int) *typeInfo {
+	t := &typeInfo{kind: kindOther, src: types.ExprString(expr)}
+	if depth > maxDepth {
+		return t
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if k, ok := basicKinds[e.Name]; ok {
+			t.kind = k
+			return t
+		}
+		decl, ok := p.decls[e.Name]
+		if !ok {
+			return t
+		}
+		if _, ok := decl.(*ast.StructType); ok {
+			t.kind, t.name = kindStruct, e.Name
+			return t
+		}
+		under := p.resolve(decl, depth+1)
+		under.src = t.src
+		return under
+	case *ast.ParenExpr:
+		return p.resolve(e.X, depth+1)
+	case *ast.StarExpr:
+		t.kind, t.elem = kindPointer, p.resolve(e.X, depth+1)
+	case *ast.ArrayType:
+		t.kind, t.elem, t.array = kindSlice, p.resolve(e.Elt, depth+1), e.Len != nil
+	case *ast.MapType:
+		t.kind, t.elem = kindMap, p.resolve(e.Value, depth+1)
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "time" && e.Sel.Name == "Time" {
+			t.kind = kindTime
+		}
+	}
+	return t
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Правила валидатора, которые умеет генерировать validgen. Пользовательские
+// правила регистрируются во время выполнения, поэтому не поддерживаются.
+var (
+	checkRules = map[string]bool{"len": true, "regexp": true, "in": true, "min": true, "max": true}
+	crossRules = map[string]bool{
+		"eqfield": true, "nefield": true, "gtfield": true, "gtefield": true, "ltfield": true, "ltefield": true,
+	}
+)
+
+const (
+	requiredDirective = "required"
+	nestedDirective   = "nested"
+)
+
+type rule struct {
+	name string
+	arg  string
+}
+
+type fieldRules struct {
+	required bool
+	nested   bool
+	checks   []rule
+	refs     []rule
+}
+
+// parseRules разбирает тэг поля с типом значений leaf и проверяет аргументы
+// так же, как Validate: ошибка тэга обнаруживается при генерации.
+func parseRules(tag string, leaf *typeInfo) (*fieldRules, error) {
+	r := &fieldRules{}
+	for _, expr := range splitRules(tag) {
+		name, arg, _ := strings.Cut(expr, ":")
+		switch {
+		case crossRules[name] && arg != "":
+			r.refs = append(r.refs, rule{name: name, arg: arg})
+		case name == requiredDirective && arg == "":
+			r.required = true
+		case name == nestedDirective && arg == "" && leaf.kind == kindStruct:
+			r.nested = true
+		case checkRules[name] && arg != "":
+			if err := checkArg(name, arg, leaf); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", expr, err)
+			}
+			r.checks = append(r.checks, rule{name: name, arg: arg})
+		case checkRules[name] || crossRules[name] || name == requiredDirective || name == nestedDirective:
+			return nil, fmt.Errorf("rule %q is invalid for %s", expr, leaf.src)
+		default:
+			return nil, fmt.Errorf("rule %q: %w", name, errUnsupported)
+		}
+	}
+	return r, nil
+}
+
+func checkArg(name, arg string, leaf *typeInfo) error {
+	switch {
+	case leaf.kind == kindString && name == "len":
+		if n, err := strconv.Atoi(arg); err != nil || n < 0 {
+			return fmt.Errorf("length must be a non-negative integer")
+		}
+	case leaf.kind == kindString && name == "regexp":
+		_, err := regexp.Compile(arg)
+		return err
+	case leaf.kind == kindString && name == "in":
+	case leaf.kind == kindInt && (name == "min" || name == "max"):
+		_, err := parseInt(arg)
+		return err
+	case leaf.kind == kindInt && name == "in":
+		_, err := parseInts(arg)
+		return err
+	default:
+		return fmt.Errorf("not applicable to %s", leaf.src)
+	}
+	return nil
+}
+
+func parseInt(s string) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
+
+func parseInts(arg string) ([]int64, error) {
+	var values []int64
+	for _, s := range strings.Split(arg, ",") {
+		n, err := parseInt(s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// splitRules делит тэг так же, как валидатор: "|" — разделитель, только если
+// за ним идёт имя известного правила.
+func splitRules(tag string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == '|' && startsWithRule(tag[i+1:]) {
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+func startsWithRule(s string) bool {
+	end := strings.IndexAny(s, ":|")
+	if end < 0 {
+		end = len(s)
+	}
+	name := s[:end]
+	return checkRules[name] || crossRules[name] || name == requiredDirective || name == nestedDirective
+}
@@ -0,0 +1,120 @@
+package hw09structvalidator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEqField  = errors.New("must be equal to field")
+	ErrNeField  = errors.New("must not be equal to field")
+	ErrGtField  = errors.New("must be greater than field")
+	ErrGteField = errors.New("must be greater than or equal to field")
+	ErrLtField  = errors.New("must be less than field")
+	ErrLteField = errors.New("must be less than or equal to field")
+)
+
+// crossRule сравнивает поле с другим полем той же структуры.
+type crossRule struct {
+	err     error
+	ordered bool // нужен порядок значений, а не только равенство
+	accept  func(cmp int) bool
+}
+
+var crossRules = map[string]crossRule{
+	"eqfield":  {err: ErrEqField, accept: func(c int) bool { return c == 0 }},
+	"nefield":  {err: ErrNeField, accept: func(c int) bool { return c != 0 }},
+	"gtfield":  {err: ErrGtField, ordered: true, accept: func(c int) bool { return c > 0 }},
+	"gtefield": {err: ErrGteField, ordered: true, accept: func(c int) bool { return c >= 0 }},
+	"ltfield":  {err: ErrLtField, ordered: true, accept: func(c int) bool { return c < 0 }},
+	"ltefield": {err: ErrLteField, ordered: true, accept: func(c int) bool { return c <= 0 }},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldRef — межполевое правило: eqfield:Password, gtfield:Start.
+type fieldRef struct {
+	name  string
+	rule  crossRule
+	other string
+	index []int // индекс поля other, заполняется при построении плана
+}
+
+// resolve находит поле other в структуре st и проверяет, что его можно
+// сравнивать с полем типа t.
+func (r *fieldRef) resolve(st, t reflect.Type) error {
+	other, ok := st.FieldByName(r.other)
+	switch {
+	case !ok || !other.IsExported():
+		return fmt.Errorf("rule %q: no exported field %q: %w", r.name, r.other, ErrInvalidTag)
+	case other.Type != t:
+		return fmt.Errorf("rule %q: field %s is %s, not %s: %w", r.name, r.other, other.Type, t, ErrInvalidTag)
+	}
+
+	elem := t
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if (r.rule.ordered && !isOrdered(elem)) || !elem.Comparable() {
+		return fmt.Errorf("rule %q is not applicable to %s: %w", r.name, t, ErrInvalidTag)
+	}
+	r.index = other.Index
+	return nil
+}
+
+// check сравнивает значение поля v с полем other структуры st.
+// Если одно из полей — nil-указатель, сравнивать нечего.
+func (r *fieldRef) check(v, st reflect.Value) error {
+	other, err := st.FieldByIndexErr(r.index)
+	if err != nil {
+		return nil //nolint:nilerr // nil встроенная структура: поля other нет
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() || other.IsNil() {
+			return nil
+		}
+		v, other = v.Elem(), other.Elem()
+	}
+	return CheckField(r.name, compare(v, other), r.other)
+}
+
+func isOrdered(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() { //nolint:exhaustive // Остальные типы не упорядочены
+	case reflect.String, reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return isInt(t.Kind()) || isUint(t.Kind())
+	}
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+// compare возвращает -1, 0 или 1 для упорядоченных типов;
+// для остальных сравнимых типов — 0 при равенстве и 1 иначе.
+func compare(a, b reflect.Value) int {
+	switch kind := a.Kind(); {
+	case a.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	case isInt(kind):
+		return cmp.Compare(a.Int(), b.Int())
+	case isUint(kind):
+		return cmp.Compare(a.Uint(), b.Uint())
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case kind == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case a.Equal(b):
+		return 0
+	default:
+		return 1
+	}
+}
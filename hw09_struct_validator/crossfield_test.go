@@ -0,0 +1,109 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	SignUp struct {
+		Login    string `validate:"len:5"`
+		Password string `validate:"nefield:Login"`
+		Confirm  string `validate:"eqfield:Password"`
+	}
+
+	Event struct {
+		Start time.Time
+		End   time.Time `validate:"gtfield:Start"`
+	}
+
+	Limits struct {
+		Min     *int
+		Max     *int    `validate:"gtefield:Min"`
+		Average float64 `validate:"ltefield:Limit|gtfield:Floor"`
+		Limit   float64
+		Floor   float64
+	}
+
+	Version struct {
+		Major uint
+	}
+
+	Release struct {
+		Version
+		Next uint `validate:"ltfield:Major"`
+	}
+
+	UnknownField struct {
+		Confirm string `validate:"eqfield:Password"`
+	}
+
+	MismatchedField struct {
+		Start time.Time
+		End   string `validate:"gtfield:Start"`
+	}
+
+	UnorderedField struct {
+		Active  bool
+		Enabled bool `validate:"gtfield:Active"`
+	}
+)
+
+func TestCrossFieldRules(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	one, two := 1, 2
+
+	tests := []struct {
+		in          interface{}
+		expectedErr error
+	}{
+		{in: SignUp{Login: "admin", Password: "secret", Confirm: "secret"}},
+		{in: Event{Start: start, End: start.Add(time.Hour)}},
+		{in: Limits{Min: &one, Max: &one, Average: 5, Limit: 5, Floor: 1}},
+		{in: Limits{Max: &one, Average: 1, Limit: 2}},
+		{in: Release{Version: Version{Major: 2}, Next: 1}},
+		{
+			in: SignUp{Login: "admin", Password: "admin", Confirm: "secret"},
+			expectedErr: ValidationErrors{
+				{Field: "Password", Err: ErrNeField},
+				{Field: "Confirm", Err: ErrEqField},
+			},
+		},
+		{
+			in:          Event{Start: start, End: start},
+			expectedErr: ValidationErrors{{Field: "End", Err: ErrGtField}},
+		},
+		{
+			in: Limits{Min: &two, Max: &one, Average: 6, Limit: 5, Floor: 6},
+			expectedErr: ValidationErrors{
+				{Field: "Max", Err: ErrGteField},
+				{Field: "Average", Err: ErrLteField},
+				{Field: "Average", Err: ErrGtField},
+			},
+		},
+		{
+			in:          Release{Version: Version{Major: 1}, Next: 1},
+			expectedErr: ValidationErrors{{Field: "Next", Err: ErrLtField}},
+		},
+		{in: UnknownField{}, expectedErr: ErrInvalidTag},
+		{in: MismatchedField{}, expectedErr: ErrInvalidTag},
+		{in: UnorderedField{}, expectedErr: ErrInvalidTag},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(tt.in)
+			requireValidationResult(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestCrossFieldMessage(t *testing.T) {
+	err := Validate(Event{End: time.Time{}})
+	require.EqualError(t, err, "validation failed: End: must be greater than field Start")
+}
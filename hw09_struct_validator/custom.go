@@ -0,0 +1,120 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidRule    = errors.New("invalid rule")
+	ErrRuleRegistered = errors.New("rule already registered")
+)
+
+// RuleFunc — пользовательское правило. field — проверяемое значение (для
+// указателей, слайсов и map — их элементы), arg — текст после ":" в тэге.
+// Возвращённая ошибка попадает в ValidationError.Err.
+type RuleFunc func(field reflect.Value, arg string) error
+
+// Validatable реализуют структуры с собственной проверкой. Validate вызывается
+// после правил из тэгов, в том числе для вложенных структур с директивой nested.
+// Ошибки ValidationErrors и ValidationError получают путь до структуры,
+// остальные становятся ValidationError с путём структуры.
+//
+// Метод не должен вызывать пакетную Validate для той же структуры: это рекурсия.
+type Validatable interface {
+	Validate() error
+}
+
+var (
+	validatableType = reflect.TypeOf((*Validatable)(nil)).Elem()
+	generatedType   = reflect.TypeOf((*Generated)(nil)).Elem()
+)
+
+var custom = struct {
+	sync.RWMutex
+	rules map[string]RuleFunc
+}{rules: make(map[string]RuleFunc)}
+
+// RegisterRule добавляет правило name для тэгов validate:"name" и validate:"name:arg".
+// Правило применимо к полям любого типа. Встроенные правила переопределить нельзя.
+func RegisterRule(name string, fn RuleFunc) error {
+	if fn == nil || name == "" || strings.ContainsAny(name, ":|") {
+		return fmt.Errorf("%q: %w", name, ErrInvalidRule)
+	}
+	if isBuiltinRule(name) {
+		return fmt.Errorf("%q: %w", name, ErrRuleRegistered)
+	}
+	if _, ok := crossRules[name]; ok {
+		return fmt.Errorf("%q: %w", name, ErrRuleRegistered)
+	}
+
+	custom.Lock()
+	defer custom.Unlock()
+	if _, ok := custom.rules[name]; ok {
+		return fmt.Errorf("%q: %w", name, ErrRuleRegistered)
+	}
+	custom.rules[name] = fn
+	// Планы, построенные до регистрации, могли запомнить ошибку "unknown rule"
+	plans.Clear()
+	return nil
+}
+
+func customRule(name string) (RuleFunc, bool) {
+	custom.RLock()
+	defer custom.RUnlock()
+	fn, ok := custom.rules[name]
+	return fn, ok
+}
+
+// callValidate вызывает Validate у структуры v, реализующей Validatable,
+// и добавляет её ошибки с путём path.
+func callValidate(path string, v reflect.Value, errs *ValidationErrors) {
+	if !v.CanInterface() {
+		return
+	}
+	if !v.Type().Implements(validatableType) {
+		// Метод с получателем-указателем: нужна адресуемая копия
+		if !v.CanAddr() {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(v)
+			v = c
+		}
+		v = v.Addr()
+	}
+
+	err := v.Interface().(Validatable).Validate()
+	var (
+		fieldsErr ValidationErrors
+		fieldErr  ValidationError
+	)
+	switch {
+	case err == nil:
+	case errors.As(err, &fieldsErr):
+		for _, e := range fieldsErr {
+			e.Field = joinPath(path, e.Field)
+			*errs = append(*errs, e)
+		}
+	case errors.As(err, &fieldErr):
+		fieldErr.Field = joinPath(path, fieldErr.Field)
+		*errs = append(*errs, fieldErr)
+	default:
+		*errs = append(*errs, ValidationError{Field: path, Err: err})
+	}
+}
+
+// joinPath добавляет к пути структуры путь поля внутри неё.
+func joinPath(path, field string) string {
+	switch {
+	case path == "":
+		return field
+	case field == "":
+		return path
+	case field[0] == '[':
+		return path + field
+	default:
+		return path + "." + field
+	}
+}
@@ -0,0 +1,154 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errOdd    = errors.New("must be even")
+	errPast   = errors.New("must be in the future")
+	errPrefix = errors.New("missing prefix")
+)
+
+type (
+	Lottery struct {
+		Numbers []int  `validate:"min:0|even"`
+		Ticket  string `validate:"prefix:TCK-"`
+	}
+
+	Meeting struct {
+		At time.Time `validate:"future"`
+	}
+
+	Period struct {
+		Days int `validate:"min:1"`
+	}
+
+	Subscription struct {
+		Plan   string `validate:"in:free,pro"`
+		Period Period `validate:"nested"`
+		Seats  int
+	}
+
+	Team struct {
+		Name         string         `validate:"len:3"`
+		Subscription *Subscription  `validate:"nested"`
+		Members      []Subscription `validate:"nested"`
+	}
+
+	Document struct {
+		Title string
+	}
+)
+
+var errNoSeats = errors.New("pro plan requires seats")
+
+// Validate с получателем-значением.
+func (s Subscription) Validate() error {
+	if s.Plan == "pro" && s.Seats == 0 {
+		return ValidationErrors{{Field: "Seats", Err: errNoSeats}}
+	}
+	return nil
+}
+
+// Validate с получателем-указателем.
+func (d *Document) Validate() error {
+	if d.Title == "" {
+		return errors.New("document without title")
+	}
+	return nil
+}
+
+func init() {
+	even := func(field reflect.Value, _ string) error {
+		if field.Int()%2 != 0 {
+			return fmt.Errorf("%w, got %d", errOdd, field.Int())
+		}
+		return nil
+	}
+	prefix := func(field reflect.Value, arg string) error {
+		if len(field.String()) < len(arg) || field.String()[:len(arg)] != arg {
+			return fmt.Errorf("%w %q", errPrefix, arg)
+		}
+		return nil
+	}
+	for name, fn := range map[string]RuleFunc{"even": even, "prefix": prefix} {
+		if err := RegisterRule(name, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	noop := func(reflect.Value, string) error { return nil }
+
+	require.ErrorIs(t, RegisterRule("", noop), ErrInvalidRule)
+	require.ErrorIs(t, RegisterRule("a:b", noop), ErrInvalidRule)
+	require.ErrorIs(t, RegisterRule("a|b", noop), ErrInvalidRule)
+	require.ErrorIs(t, RegisterRule("nil", nil), ErrInvalidRule)
+	require.ErrorIs(t, RegisterRule("len", noop), ErrRuleRegistered)
+	require.ErrorIs(t, RegisterRule("required", noop), ErrRuleRegistered)
+	require.ErrorIs(t, RegisterRule("eqfield", noop), ErrRuleRegistered)
+	require.ErrorIs(t, RegisterRule("even", noop), ErrRuleRegistered)
+
+	requireValidationResult(t, nil, Validate(Lottery{Numbers: []int{2, 4}, Ticket: "TCK-1"}))
+	requireValidationResult(t, ValidationErrors{
+		{Field: "Numbers[0]", Err: ErrMin},
+		{Field: "Numbers[1]", Err: errOdd},
+		{Field: "Ticket", Err: errPrefix},
+	}, Validate(Lottery{Numbers: []int{-2, 3}, Ticket: "T"}))
+}
+
+func TestRegisterRuleAfterValidate(t *testing.T) {
+	// При повторном запуске (-count) правило уже зарегистрировано
+	if _, ok := customRule("future"); !ok {
+		require.ErrorIs(t, Validate(Meeting{}), ErrInvalidTag)
+
+		err := RegisterRule("future", func(field reflect.Value, _ string) error {
+			if !field.Interface().(time.Time).After(time.Now()) {
+				return errPast
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, Validate(Meeting{At: time.Now().Add(time.Hour)}))
+	requireValidationResult(t, ValidationErrors{{Field: "At", Err: errPast}}, Validate(Meeting{}))
+}
+
+func TestValidatable(t *testing.T) {
+	valid := Team{
+		Name:         "dev",
+		Subscription: &Subscription{Plan: "pro", Period: Period{Days: 30}, Seats: 5},
+	}
+	require.NoError(t, Validate(valid))
+
+	invalid := Team{
+		Name:         "developers",
+		Subscription: &Subscription{Plan: "pro", Period: Period{Days: 0}},
+		Members:      []Subscription{{Plan: "free", Period: Period{Days: 1}}, {Plan: "pro", Period: Period{Days: 1}}},
+	}
+	requireValidationResult(t, ValidationErrors{
+		{Field: "Name", Err: ErrLen},
+		{Field: "Subscription.Period.Days", Err: ErrMin},
+		{Field: "Subscription.Seats", Err: errNoSeats},
+		{Field: "Members[1].Seats", Err: errNoSeats},
+	}, Validate(invalid))
+
+	err := Validate(Subscription{Plan: "pro", Period: Period{Days: 1}})
+	require.EqualError(t, err, "validation failed: Seats: pro plan requires seats")
+
+	require.NoError(t, Validate(Document{Title: "README"}))
+	err = Validate(Document{})
+	require.EqualError(t, err, "validation failed: document without title")
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Empty(t, errs[0].Field)
+}
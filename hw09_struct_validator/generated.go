@@ -0,0 +1,107 @@
+package hw09structvalidator
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+// Проверки встроенных правил. Их вызывают и Validate, и код, созданный
+// cmd/validgen, поэтому ошибки обоих валидаторов совпадают.
+
+// Generated реализуют типы с методом Validate, созданным cmd/validgen.
+// Такой метод сам проверяет тэги, поэтому Validate пакета не вызывает его
+// как Validatable.
+type Generated interface {
+	Validatable
+	GeneratedValidator()
+}
+
+// CheckLen — правило len: длина строки в символах ровно n.
+func CheckLen(s string, n int) error {
+	if got := utf8.RuneCountInString(s); got != n {
+		return &RuleError{Rule: "len", Param: n, Actual: got, Err: ErrLen}
+	}
+	return nil
+}
+
+// CheckRegexp — правило regexp.
+func CheckRegexp(s string, re *regexp.Regexp) error {
+	if !re.MatchString(s) {
+		return &RuleError{Rule: "regexp", Param: re.String(), Actual: s, Err: ErrRegexp}
+	}
+	return nil
+}
+
+// CheckStringIn — правило in для строк.
+func CheckStringIn(s string, allowed []string) error {
+	if slices.Contains(allowed, s) {
+		return nil
+	}
+	return &RuleError{Rule: "in", Param: allowed, Actual: s, Err: ErrIn}
+}
+
+// CheckMin — правило min.
+func CheckMin(n, limit int64) error {
+	if n < limit {
+		return &RuleError{Rule: "min", Param: limit, Actual: n, Err: ErrMin}
+	}
+	return nil
+}
+
+// CheckMax — правило max.
+func CheckMax(n, limit int64) error {
+	if n > limit {
+		return &RuleError{Rule: "max", Param: limit, Actual: n, Err: ErrMax}
+	}
+	return nil
+}
+
+// CheckIntIn — правило in для чисел.
+func CheckIntIn(n int64, allowed []int64) error {
+	if slices.Contains(allowed, n) {
+		return nil
+	}
+	return &RuleError{Rule: "in", Param: allowed, Actual: n, Err: ErrIn}
+}
+
+// CheckField — межполевое правило rule (eqfield, gtfield...) по результату
+// сравнения c поля с полем other: -1, 0 или 1.
+func CheckField(rule string, c int, other string) error {
+	r, ok := crossRules[rule]
+	if !ok {
+		panic("hw09structvalidator: unknown cross-field rule " + rule)
+	}
+	if !r.accept(c) {
+		return &RuleError{Rule: rule, Param: other, Err: r.err}
+	}
+	return nil
+}
+
+// CompareEqual сравнивает значения без порядка: 0 при равенстве, иначе 1.
+func CompareEqual[T comparable](a, b T) int {
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+// IsZero — правило required для сравнимых типов.
+func IsZero[T comparable](v T) bool {
+	var zero T
+	return v == zero
+}
+
+// SortedKeys возвращает ключи map в порядке обхода Validate.
+func SortedKeys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+	return keys
+}
@@ -0,0 +1,21 @@
+package hw09structvalidator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecks(t *testing.T) {
+	require.NoError(t, CheckIntIn(404, []int64{200, 404}))
+	require.EqualError(t, CheckIntIn(1, []int64{200, 404}), "not in allowed values 200,404, got 1")
+	require.EqualError(t, CheckStringIn("x", []string{"a", "b"}), `not in allowed values a,b, got "x"`)
+	require.ErrorIs(t, CheckField("ltfield", 0, "Max"), ErrLtField)
+	require.NoError(t, CheckField("ltefield", 0, "Max"))
+	require.Panics(t, func() { _ = CheckField("lenfield", 0, "Max") })
+
+	require.True(t, IsZero(""))
+	require.False(t, IsZero(Address{City: "Msk"}))
+	require.Equal(t, 0, CompareEqual(Address{}, Address{}))
+	require.Equal(t, []int{1, 10, 2}, SortedKeys(map[int]bool{2: true, 10: true, 1: true}))
+}
@@ -0,0 +1,69 @@
+// Package models — структуры с тэгами validate и методами Validate от validgen.
+// На них тесты сверяют сгенерированный и рефлексивный валидаторы.
+package models
+
+import "time"
+
+//go:generate go run github.com/fixme_my_friend/hw09_struct_validator/cmd/validgen
+
+type UserRole string
+
+type Phones []string
+
+type User struct {
+	ID     string   `json:"id" validate:"len:36"`
+	Name   string   `validate:"required"`
+	Age    int      `validate:"min:18|max:50"`
+	Email  string   `validate:"regexp:^\\w+@\\w+\\.\\w+$"`
+	Role   UserRole `validate:"in:admin,stuff"`
+	Phones Phones   `validate:"len:11"`
+	Code   int16    `validate:"in:200, 404,500"`
+	Login  string   `validate:"regexp:^(admin|user)\\d*$|len:6"`
+}
+
+type Address struct {
+	City string `validate:"len:3"`
+	Zip  string `validate:"regexp:^\\d{6}$"`
+}
+
+type Order struct {
+	ID       int      `validate:"min:1"`
+	Address  *Address `validate:"required|nested"`
+	Comment  *string  `validate:"len:5"`
+	Discount *int     `validate:"max:50"`
+}
+
+type Audit struct {
+	Author string `validate:"len:5"`
+}
+
+type Customer struct {
+	Audit     `validate:"nested"`
+	Name      string              `validate:"required"`
+	Home      Address             `validate:"nested"`
+	Orders    []Order             `validate:"nested"`
+	Addresses map[string]Address  `validate:"nested"`
+	Tags      map[string]string   `validate:"len:2"`
+	Scores    [][]int             `validate:"min:0"`
+	Levels    [3]int              `validate:"required|max:10"`
+	Backups   map[int][]*Address  `validate:"nested"`
+	Manager   *Customer           `validate:"nested"`
+	Extra     map[string]struct{} `validate:"required"`
+}
+
+type Event struct {
+	Title string    `validate:"required|nefield:Owner"`
+	Owner string    `validate:"len:4"`
+	Start time.Time `validate:"required"`
+	End   time.Time `validate:"gtfield:Start"`
+}
+
+type Limits struct {
+	Min      *int
+	Max      *int    `validate:"gtefield:Min"`
+	Average  float64 `validate:"ltefield:Limit|gtfield:Floor"`
+	Limit    float64
+	Floor    float64
+	Password string
+	Confirm  string `validate:"eqfield:Password"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	validator "github.com/fixme_my_friend/hw09_struct_validator"
+	"github.com/stretchr/testify/require"
+)
+
+func validUser() User {
+	return User{
+		ID:     "123e4567-e89b-12d3-a456-426614174000",
+		Name:   "Ivan",
+		Age:    30,
+		Email:  "user@example.com",
+		Role:   "admin",
+		Phones: Phones{"79001234567"},
+		Code:   404,
+		Login:  "admin1",
+	}
+}
+
+func validCustomer() Customer {
+	return Customer{
+		Audit:     Audit{Author: "admin"},
+		Name:      "Ivan",
+		Home:      Address{City: "Msk", Zip: "101000"},
+		Orders:    []Order{{ID: 1, Address: &Address{City: "Spb", Zip: "190000"}}},
+		Addresses: map[string]Address{"work": {City: "Tvr", Zip: "170000"}},
+		Levels:    [3]int{1},
+		Extra:     map[string]struct{}{},
+	}
+}
+
+func TestGeneratedMatchesReflection(t *testing.T) {
+	comment, discount, one, two := "short comment", 60, 1, 2
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	invalidUser := User{
+		ID: "short", Age: 51, Email: "not an email", Role: "guest",
+		Phones: Phones{"79001234567", "123"}, Code: 201, Login: "guest",
+	}
+
+	invalidCustomer := validCustomer()
+	invalidCustomer.Author = "bot"
+	invalidCustomer.Name = ""
+	invalidCustomer.Home.Zip = "abc"
+	invalidCustomer.Orders = []Order{
+		{ID: 0},
+		{ID: 3, Address: &Address{City: "Moscow", Zip: "1"}, Comment: &comment, Discount: &discount},
+	}
+	invalidCustomer.Addresses = map[string]Address{"work": {City: "Tver"}, "home": {City: "Msk", Zip: "x"}}
+	invalidCustomer.Tags = map[string]string{"lang": "rus", "os": "linux"}
+	invalidCustomer.Scores = [][]int{{1, -1}, nil, {-2}}
+	invalidCustomer.Levels = [3]int{11, 0, 12}
+	invalidCustomer.Backups = map[int][]*Address{10: {nil, {City: "x"}}, 2: {{Zip: "1"}}}
+	invalidCustomer.Manager = &Customer{Name: "Boss", Orders: []Order{{ID: 1}}}
+	invalidCustomer.Extra = nil
+
+	tests := []validator.Generated{
+		validUser(),
+		invalidUser,
+		User{},
+		validCustomer(),
+		invalidCustomer,
+		Customer{},
+		Order{},
+		Event{Title: "Demo", Owner: "anna", Start: start, End: start.Add(time.Hour)},
+		Event{Title: "anna", Owner: "anna", Start: start, End: start},
+		Event{},
+		Limits{Min: &one, Max: &one, Average: 2, Limit: 2, Floor: 1, Password: "x", Confirm: "x"},
+		Limits{Min: &two, Max: &one, Average: 3, Limit: 2, Floor: 3, Password: "x", Confirm: "y"},
+		Limits{Max: &one},
+	}
+
+	for i, v := range tests {
+		t.Run(fmt.Sprintf("case %d %T", i, v), func(t *testing.T) {
+			requireSameErrors(t, validator.Validate(v), v.Validate())
+		})
+	}
+}
+
+// requireSameErrors сравнивает ошибки поэлементно: путь, текст и исходную ошибку правила.
+func requireSameErrors(t *testing.T, reflective, generated error) {
+	t.Helper()
+
+	if reflective == nil {
+		require.NoError(t, generated)
+		return
+	}
+	var expected, actual validator.ValidationErrors
+	require.ErrorAs(t, reflective, &expected)
+	require.ErrorAs(t, generated, &actual)
+	require.Equal(t, reflective.Error(), generated.Error())
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].Field, actual[i].Field)
+		require.Equal(t, sentinel(expected[i].Err), sentinel(actual[i].Err))
+	}
+}
+
+func sentinel(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+func TestGeneratedIsNotCalledByReflection(t *testing.T) {
+	err := validator.Validate(Order{})
+	var errs validator.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2, "generated Validate must not be called as Validatable")
+}
+
+func BenchmarkValidateUser(b *testing.B) {
+	user := validUser()
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := user.Validate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := validator.Validate(user); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,274 @@
+// Code generated by validgen; DO NOT EDIT.
+
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	validator "github.com/fixme_my_friend/hw09_struct_validator"
+)
+
+var validgenRegexp0 = regexp.MustCompile("^\\w+@\\w+\\.\\w+$")
+var validgenRegexp1 = regexp.MustCompile("^(admin|user)\\d*$")
+var validgenRegexp2 = regexp.MustCompile("^\\d{6}$")
+
+// Validate проверяет User по тэгам validate без рефлексии.
+func (x User) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (User) GeneratedValidator() {}
+
+func (x *User) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if err := validator.CheckLen(string(x.ID), 36); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "ID", Err: err})
+	}
+	if validator.IsZero(x.Name) {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Name", Err: validator.ErrRequired})
+	}
+	if err := validator.CheckMin(int64(x.Age), 18); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Age", Err: err})
+	}
+	if err := validator.CheckMax(int64(x.Age), 50); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Age", Err: err})
+	}
+	if err := validator.CheckRegexp(string(x.Email), validgenRegexp0); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Email", Err: err})
+	}
+	if err := validator.CheckStringIn(string(x.Role), []string{"admin", "stuff"}); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Role", Err: err})
+	}
+	for i0 := range x.Phones {
+		p0 := prefix + "Phones[" + strconv.Itoa(i0) + "]"
+		if err := validator.CheckLen(string(x.Phones[i0]), 11); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: p0, Err: err})
+		}
+	}
+	if err := validator.CheckIntIn(int64(x.Code), []int64{200, 404, 500}); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Code", Err: err})
+	}
+	if err := validator.CheckRegexp(string(x.Login), validgenRegexp1); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Login", Err: err})
+	}
+	if err := validator.CheckLen(string(x.Login), 6); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Login", Err: err})
+	}
+}
+
+// Validate проверяет Address по тэгам validate без рефлексии.
+func (x Address) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Address) GeneratedValidator() {}
+
+func (x *Address) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if err := validator.CheckLen(string(x.City), 3); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "City", Err: err})
+	}
+	if err := validator.CheckRegexp(string(x.Zip), validgenRegexp2); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Zip", Err: err})
+	}
+}
+
+// Validate проверяет Order по тэгам validate без рефлексии.
+func (x Order) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Order) GeneratedValidator() {}
+
+func (x *Order) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if err := validator.CheckMin(int64(x.ID), 1); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "ID", Err: err})
+	}
+	if x.Address == nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Address", Err: validator.ErrRequired})
+	} else {
+		(*x.Address).validateTags(prefix+"Address.", errs)
+	}
+	if x.Comment != nil {
+		if err := validator.CheckLen(string(*x.Comment), 5); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: prefix + "Comment", Err: err})
+		}
+	}
+	if x.Discount != nil {
+		if err := validator.CheckMax(int64(*x.Discount), 50); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: prefix + "Discount", Err: err})
+		}
+	}
+}
+
+// Validate проверяет Audit по тэгам validate без рефлексии.
+func (x Audit) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Audit) GeneratedValidator() {}
+
+func (x *Audit) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if err := validator.CheckLen(string(x.Author), 5); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Author", Err: err})
+	}
+}
+
+// Validate проверяет Customer по тэгам validate без рефлексии.
+func (x Customer) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Customer) GeneratedValidator() {}
+
+func (x *Customer) validateTags(prefix string, errs *validator.ValidationErrors) {
+	x.Audit.validateTags(prefix, errs)
+	if validator.IsZero(x.Name) {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Name", Err: validator.ErrRequired})
+	}
+	x.Home.validateTags(prefix+"Home.", errs)
+	for i0 := range x.Orders {
+		p0 := prefix + "Orders[" + strconv.Itoa(i0) + "]"
+		x.Orders[i0].validateTags(p0+".", errs)
+	}
+	for _, k0 := range validator.SortedKeys(x.Addresses) {
+		e0 := x.Addresses[k0]
+		p0 := prefix + "Addresses[" + fmt.Sprint(k0) + "]"
+		e0.validateTags(p0+".", errs)
+	}
+	for _, k0 := range validator.SortedKeys(x.Tags) {
+		e0 := x.Tags[k0]
+		p0 := prefix + "Tags[" + fmt.Sprint(k0) + "]"
+		if err := validator.CheckLen(string(e0), 2); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: p0, Err: err})
+		}
+	}
+	for i0 := range x.Scores {
+		p0 := prefix + "Scores[" + strconv.Itoa(i0) + "]"
+		for i1 := range x.Scores[i0] {
+			p1 := p0 + "[" + strconv.Itoa(i1) + "]"
+			if err := validator.CheckMin(int64(x.Scores[i0][i1]), 0); err != nil {
+				*errs = append(*errs, validator.ValidationError{Field: p1, Err: err})
+			}
+		}
+	}
+	if validator.IsZero(x.Levels) {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Levels", Err: validator.ErrRequired})
+	} else {
+		for i0 := range x.Levels {
+			p0 := prefix + "Levels[" + strconv.Itoa(i0) + "]"
+			if err := validator.CheckMax(int64(x.Levels[i0]), 10); err != nil {
+				*errs = append(*errs, validator.ValidationError{Field: p0, Err: err})
+			}
+		}
+	}
+	for _, k0 := range validator.SortedKeys(x.Backups) {
+		e0 := x.Backups[k0]
+		p0 := prefix + "Backups[" + fmt.Sprint(k0) + "]"
+		for i1 := range e0 {
+			p1 := p0 + "[" + strconv.Itoa(i1) + "]"
+			if e0[i1] != nil {
+				(*e0[i1]).validateTags(p1+".", errs)
+			}
+		}
+	}
+	if x.Manager != nil {
+		(*x.Manager).validateTags(prefix+"Manager.", errs)
+	}
+	if x.Extra == nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Extra", Err: validator.ErrRequired})
+	}
+}
+
+// Validate проверяет Event по тэгам validate без рефлексии.
+func (x Event) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Event) GeneratedValidator() {}
+
+func (x *Event) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if validator.IsZero(x.Title) {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Title", Err: validator.ErrRequired})
+	} else {
+		if err := validator.CheckField("nefield", cmp.Compare(x.Title, x.Owner), "Owner"); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: prefix + "Title", Err: err})
+		}
+	}
+	if err := validator.CheckLen(string(x.Owner), 4); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Owner", Err: err})
+	}
+	if validator.IsZero(x.Start) {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Start", Err: validator.ErrRequired})
+	}
+	if err := validator.CheckField("gtfield", x.End.Compare(x.Start), "Start"); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "End", Err: err})
+	}
+}
+
+// Validate проверяет Limits по тэгам validate без рефлексии.
+func (x Limits) Validate() error {
+	var errs validator.ValidationErrors
+	x.validateTags("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GeneratedValidator отмечает, что Validate создан validgen.
+func (Limits) GeneratedValidator() {}
+
+func (x *Limits) validateTags(prefix string, errs *validator.ValidationErrors) {
+	if x.Max != nil && x.Min != nil {
+		if err := validator.CheckField("gtefield", cmp.Compare(*x.Max, *x.Min), "Min"); err != nil {
+			*errs = append(*errs, validator.ValidationError{Field: prefix + "Max", Err: err})
+		}
+	}
+	if err := validator.CheckField("ltefield", cmp.Compare(x.Average, x.Limit), "Limit"); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Average", Err: err})
+	}
+	if err := validator.CheckField("gtfield", cmp.Compare(x.Average, x.Floor), "Floor"); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Average", Err: err})
+	}
+	if err := validator.CheckField("eqfield", cmp.Compare(x.Confirm, x.Password), "Password"); err != nil {
+		*errs = append(*errs, validator.ValidationError{Field: prefix + "Confirm", Err: err})
+	}
+}
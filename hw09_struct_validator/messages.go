@@ -0,0 +1,192 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Lang — язык сообщений об ошибках.
+type Lang string
+
+const (
+	LangEN Lang = "en"
+	LangRU Lang = "ru"
+)
+
+// Коды правил без параметров и сравнений.
+const ruleRequired = "required"
+
+// RuleError — нарушение правила: код, параметр из тэга и фактическое значение.
+// Unwrap возвращает ошибку правила (ErrLen, ErrMin...) или ошибку RegisterRule.
+type RuleError struct {
+	Rule   string
+	Param  interface{}
+	Actual interface{}
+	Err    error
+}
+
+func (e *RuleError) Error() string {
+	return e.Message(LangEN)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Message возвращает сообщение на языке lang. Для правил без шаблона
+// (зарегистрированных через RegisterRule) — текст их ошибки.
+func (e *RuleError) Message(lang Lang) string {
+	template, ok := messageTemplate(lang, e.Rule)
+	if !ok {
+		return e.Err.Error()
+	}
+	return strings.NewReplacer(
+		"{param}", formatValue(e.Param, false),
+		"{actual}", formatValue(e.Actual, true),
+	).Replace(template)
+}
+
+// formatValue печатает параметр или значение для шаблона: списки через
+// запятую, строки-значения в кавычках.
+func formatValue(v interface{}, quote bool) string {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case []int64:
+		parts := make([]string, len(v))
+		for i, n := range v {
+			parts[i] = strconv.FormatInt(n, 10)
+		}
+		return strings.Join(parts, ",")
+	case string:
+		if quote {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var catalog = struct {
+	sync.RWMutex
+	messages map[Lang]map[string]string
+}{messages: map[Lang]map[string]string{
+	LangEN: {
+		"len":        "invalid length: must be {param}, got {actual}",
+		"min":        "less than minimum {param}, got {actual}",
+		"max":        "greater than maximum {param}, got {actual}",
+		"in":         "not in allowed values {param}, got {actual}",
+		"regexp":     `does not match pattern "{param}"`,
+		ruleRequired: "required value is missing",
+		"eqfield":    "must be equal to field {param}",
+		"nefield":    "must not be equal to field {param}",
+		"gtfield":    "must be greater than field {param}",
+		"gtefield":   "must be greater than or equal to field {param}",
+		"ltfield":    "must be less than field {param}",
+		"ltefield":   "must be less than or equal to field {param}",
+	},
+	LangRU: {
+		"len":        "неверная длина: нужно {param}, получено {actual}",
+		"min":        "меньше минимума {param}, получено {actual}",
+		"max":        "больше максимума {param}, получено {actual}",
+		"in":         "нет среди допустимых значений {param}, получено {actual}",
+		"regexp":     `не соответствует шаблону "{param}"`,
+		ruleRequired: "обязательное значение не задано",
+		"eqfield":    "должно совпадать с полем {param}",
+		"nefield":    "не должно совпадать с полем {param}",
+		"gtfield":    "должно быть больше поля {param}",
+		"gtefield":   "должно быть не меньше поля {param}",
+		"ltfield":    "должно быть меньше поля {param}",
+		"ltefield":   "должно быть не больше поля {param}",
+	},
+}}
+
+// SetMessage задаёт шаблон сообщения правила rule на языке lang: перевод для
+// правила из RegisterRule или новый язык. В шаблоне доступны {param} и {actual}.
+func SetMessage(lang Lang, rule, template string) {
+	catalog.Lock()
+	defer catalog.Unlock()
+	if catalog.messages[lang] == nil {
+		catalog.messages[lang] = make(map[string]string)
+	}
+	catalog.messages[lang][rule] = template
+}
+
+// messageTemplate ищет шаблон на языке lang, а если перевода нет — на английском.
+func messageTemplate(lang Lang, rule string) (string, bool) {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	if template, ok := catalog.messages[lang][rule]; ok {
+		return template, true
+	}
+	template, ok := catalog.messages[LangEN][rule]
+	return template, ok
+}
+
+// ErrorDetail — описание ошибки поля для ответа API.
+type ErrorDetail struct {
+	Field   string      `json:"field"`
+	Rule    string      `json:"rule,omitempty"`
+	Param   interface{} `json:"param,omitempty"`
+	Message string      `json:"message"`
+}
+
+// Rule возвращает код нарушенного правила или "", если ошибку вернул Validatable.
+func (e ValidationError) Rule() string {
+	var ruleErr *RuleError
+	switch {
+	case errors.As(e.Err, &ruleErr):
+		return ruleErr.Rule
+	case errors.Is(e.Err, ErrRequired):
+		return ruleRequired
+	default:
+		return ""
+	}
+}
+
+// Param возвращает параметр правила из тэга: 18 для min:18, ["a","b"] для in:a,b.
+func (e ValidationError) Param() interface{} {
+	var ruleErr *RuleError
+	if errors.As(e.Err, &ruleErr) {
+		return ruleErr.Param
+	}
+	return nil
+}
+
+// Message возвращает сообщение об ошибке поля на языке lang.
+func (e ValidationError) Message(lang Lang) string {
+	var ruleErr *RuleError
+	if errors.As(e.Err, &ruleErr) {
+		return ruleErr.Message(lang)
+	}
+	if errors.Is(e.Err, ErrRequired) {
+		if template, ok := messageTemplate(lang, ruleRequired); ok {
+			return template
+		}
+	}
+	return e.Err.Error()
+}
+
+// Localize описывает ошибку для ответа API на языке lang.
+func (e ValidationError) Localize(lang Lang) ErrorDetail {
+	return ErrorDetail{Field: e.Field, Rule: e.Rule(), Param: e.Param(), Message: e.Message(lang)}
+}
+
+// MarshalJSON кодирует ошибку как ErrorDetail на английском.
+func (e ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Localize(LangEN))
+}
+
+// Localize описывает все ошибки на языке lang: json.Marshal(errs.Localize(LangRU)).
+func (v ValidationErrors) Localize(lang Lang) []ErrorDetail {
+	details := make([]ErrorDetail, len(v))
+	for i, e := range v {
+		details[i] = e.Localize(lang)
+	}
+	return details
+}
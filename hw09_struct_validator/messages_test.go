@@ -0,0 +1,93 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SignUpForm struct {
+	Name     string `validate:"required"`
+	Age      int    `validate:"min:18"`
+	Role     string `validate:"in:admin,stuff"`
+	Login    string `validate:"regexp:^\\w+$"`
+	Password string
+	Confirm  string `validate:"eqfield:Password"`
+	Lucky    int    `validate:"even"`
+}
+
+func invalidSignUp() SignUpForm {
+	return SignUpForm{Age: 16, Role: "guest", Login: "a b", Password: "x", Confirm: "y", Lucky: 7}
+}
+
+func TestValidationErrorRule(t *testing.T) {
+	var errs ValidationErrors
+	require.ErrorAs(t, Validate(invalidSignUp()), &errs)
+
+	tests := []struct {
+		field string
+		rule  string
+		param interface{}
+	}{
+		{field: "Name", rule: "required"},
+		{field: "Age", rule: "min", param: int64(18)},
+		{field: "Role", rule: "in", param: []string{"admin", "stuff"}},
+		{field: "Login", rule: "regexp", param: `^\w+$`},
+		{field: "Confirm", rule: "eqfield", param: "Password"},
+		{field: "Lucky", rule: "even"},
+	}
+	require.Len(t, errs, len(tests))
+	for i, tc := range tests {
+		require.Equal(t, tc.field, errs[i].Field)
+		require.Equal(t, tc.rule, errs[i].Rule())
+		require.Equal(t, tc.param, errs[i].Param())
+	}
+	require.ErrorIs(t, errs[1], ErrMin)
+
+	plain := ValidationError{Field: "Seats", Err: errNoSeats}
+	require.Empty(t, plain.Rule())
+	require.Nil(t, plain.Param())
+	require.Equal(t, "pro plan requires seats", plain.Message(LangRU))
+}
+
+func TestValidationErrorsJSON(t *testing.T) {
+	data, err := json.Marshal(Validate(invalidSignUp()))
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"field": "Name", "rule": "required", "message": "required value is missing"},
+		{"field": "Age", "rule": "min", "param": 18, "message": "less than minimum 18, got 16"},
+		{"field": "Role", "rule": "in", "param": ["admin", "stuff"],
+			"message": "not in allowed values admin,stuff, got \"guest\""},
+		{"field": "Login", "rule": "regexp", "param": "^\\w+$", "message": "does not match pattern \"^\\w+$\""},
+		{"field": "Confirm", "rule": "eqfield", "param": "Password", "message": "must be equal to field Password"},
+		{"field": "Lucky", "rule": "even", "message": "must be even, got 7"}
+	]`, string(data))
+}
+
+func TestLocalize(t *testing.T) {
+	var errs ValidationErrors
+	require.ErrorAs(t, Validate(invalidSignUp()), &errs)
+
+	messages := make([]string, 0, len(errs))
+	for _, d := range errs.Localize(LangRU) {
+		messages = append(messages, d.Message)
+	}
+	require.Equal(t, []string{
+		"обязательное значение не задано",
+		"меньше минимума 18, получено 16",
+		`нет среди допустимых значений admin,stuff, получено "guest"`,
+		`не соответствует шаблону "^\w+$"`,
+		"должно совпадать с полем Password",
+		"must be even, got 7",
+	}, messages)
+
+	// Неизвестный язык и отсутствующий перевод — английский
+	require.Equal(t, "less than minimum 18, got 16", errs[1].Message("de"))
+
+	SetMessage(LangRU, "odd", "должно быть нечётным, получено {actual}")
+	ruleErr := &RuleError{Rule: "odd", Actual: 4, Err: errors.New("must be odd")}
+	require.Equal(t, "должно быть нечётным, получено 4", ruleErr.Message(LangRU))
+	require.Equal(t, "must be odd", ruleErr.Message(LangEN))
+}
@@ -0,0 +1,109 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structPlan — разобранные тэги структуры: какие поля и какими правилами проверять.
+type structPlan struct {
+	fields      []fieldPlan
+	validatable bool // структура или указатель на неё реализует Validatable
+	// Программная ошибка в тэге поля errField; возвращается при каждой проверке.
+	errField string
+	err      error
+}
+
+// fieldPlan — проверяемое поле структуры.
+type fieldPlan struct {
+	index  int
+	name   string
+	inline bool // встроенная структура с nested: поля без имени типа в пути
+	rules  *fieldRules
+}
+
+// plans кэширует *structPlan по reflect.Type: тэги разбираются и regexp
+// компилируются один раз на тип, а не при каждом вызове Validate.
+var plans sync.Map
+
+// planFor возвращает план для структуры t, строя его при первом обращении.
+// Вложенные структуры получают свои планы лениво, поэтому рекурсивные типы
+// (type Node struct{ Next *Node }) не зацикливают построение.
+func planFor(t reflect.Type) *structPlan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*structPlan)
+	}
+	p, _ := plans.LoadOrStore(t, buildPlan(t))
+	return p.(*structPlan)
+}
+
+func buildPlan(t reflect.Type) *structPlan {
+	plan := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		// Встроенная структура неэкспортируемого типа тоже неэкспортируемое поле:
+		// её значение нельзя получить через Interface, поэтому она пропускается
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		rules, err := parseTag(tag, field.Type)
+		for j := 0; err == nil && j < len(rules.refs); j++ {
+			err = rules.refs[j].resolve(t, field.Type)
+		}
+		if err != nil {
+			return &structPlan{errField: field.Name, err: err}
+		}
+		plan.fields = append(plan.fields, fieldPlan{
+			index:  i,
+			name:   field.Name,
+			inline: field.Anonymous && rules.nested,
+			rules:  rules,
+		})
+	}
+	plan.validatable = t.Implements(validatableType) || reflect.PointerTo(t).Implements(validatableType)
+	// Созданный validgen метод Validate повторил бы проверки тэгов
+	if t.Implements(generatedType) || reflect.PointerTo(t).Implements(generatedType) {
+		plan.validatable = false
+	}
+	return plan
+}
+
+// validate проверяет структуру v по плану, добавляя prefix к путям полей.
+func (p *structPlan) validate(prefix string, v reflect.Value, errs *ValidationErrors) error {
+	if p.err != nil {
+		return fmt.Errorf("field %s%s: %w", prefix, p.errField, p.err)
+	}
+	for i := range p.fields {
+		f := &p.fields[i]
+		path := prefix + f.name
+		if f.inline {
+			path = structPath(prefix)
+		}
+		field := v.Field(f.index)
+		if err := validateField(path, field, f.rules, errs); err != nil {
+			return err
+		}
+		if f.rules.required && field.IsZero() {
+			continue
+		}
+		for j := range f.rules.refs {
+			if err := f.rules.refs[j].check(field, v); err != nil {
+				*errs = append(*errs, ValidationError{Field: path, Err: err})
+			}
+		}
+	}
+
+	if p.validatable {
+		callValidate(structPath(prefix), v, errs)
+	}
+	return nil
+}
+
+// structPath — путь до самой структуры по префиксу её полей: "Orders[1]." -> "Orders[1]".
+func structPath(prefix string) string {
+	return strings.TrimSuffix(prefix, ".")
+}
@@ -0,0 +1,98 @@
+package hw09structvalidator
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Node struct {
+	Name string `validate:"len:1"`
+	Next *Node  `validate:"nested"`
+}
+
+func TestPlanFor(t *testing.T) {
+	plan := planFor(reflect.TypeOf(User{}))
+	require.Same(t, plan, planFor(reflect.TypeOf(User{})))
+	require.NoError(t, plan.err)
+
+	names := make([]string, 0, len(plan.fields))
+	for _, f := range plan.fields {
+		names = append(names, f.name)
+	}
+	require.Equal(t, []string{"ID", "Age", "Email", "Role", "Phones"}, names)
+
+	plan = planFor(reflect.TypeOf(BadRegexp{}))
+	require.Equal(t, "Name", plan.errField)
+	require.ErrorIs(t, plan.err, ErrInvalidTag)
+}
+
+func TestValidateRecursiveType(t *testing.T) {
+	list := &Node{Name: "a", Next: &Node{Name: "b", Next: &Node{Name: "cd"}}}
+	requireValidationResult(t, ValidationErrors{{Field: "Next.Next.Name", Err: ErrLen}}, Validate(list))
+}
+
+func TestValidateConcurrent(t *testing.T) {
+	invalid := validCustomer()
+	invalid.Orders = append(invalid.Orders, Order{ID: 1, Address: &Address{City: "Spb", Zip: "x"}})
+	expected := ValidationErrors{{Field: "Orders[1].Address.Zip", Err: ErrRegexp}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, Validate(validCustomer()))
+			requireValidationResult(t, expected, Validate(invalid))
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkValidate(b *testing.B) {
+	user := validUser()
+	invalidUser := validUser()
+	invalidUser.Age = 10
+	invalidUser.Phones = []string{"123"}
+
+	b.Run("valid", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := Validate(user); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("invalid", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := Validate(invalidUser); err == nil {
+				b.Fatal("error expected")
+			}
+		}
+	})
+
+	// Без кэша: тэги разбираются и regexp компилируются на каждый вызов.
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			plans.Delete(reflect.TypeOf(user))
+			if err := Validate(user); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("nested", func(b *testing.B) {
+		customer := validCustomer()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := Validate(&customer); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ошибки валидации, которыми завёрнуты ValidationError.Err.
+var (
+	ErrLen      = errors.New("invalid length")
+	ErrMin      = errors.New("less than minimum")
+	ErrMax      = errors.New("greater than maximum")
+	ErrIn       = errors.New("not in allowed values")
+	ErrRegexp   = errors.New("does not match pattern")
+	ErrRequired = errors.New("required value is missing")
+)
+
+// check проверяет одно значение и возвращает ошибку валидации.
+type check func(v reflect.Value) error
+
+// ruleFunc разбирает аргумент правила и создаёт проверку.
+type ruleFunc func(arg string) (check, error)
+
+var stringRules = map[string]ruleFunc{
+	"len":    lenRule,
+	"regexp": regexpRule,
+	"in":     stringInRule,
+}
+
+var intRules = map[string]ruleFunc{
+	"min": minRule,
+	"max": maxRule,
+	"in":  intInRule,
+}
+
+// Директивы без аргумента, применимые к полю любого типа.
+const (
+	requiredDirective = "required"
+	nestedDirective   = "nested"
+)
+
+// fieldRules — разобранный тэг поля.
+type fieldRules struct {
+	required bool       // значение не должно быть нулевым (nil для указателя)
+	nested   bool       // проверять вложенные структуры
+	checks   []check    // проверки для значений внутри указателей, слайсов и map
+	refs     []fieldRef // сравнения с другими полями структуры
+}
+
+// parseTag разбирает тэг поля типа t. Проверки относятся к значениям,
+// до которых validateValue доходит через указатели, слайсы и значения map.
+func parseTag(tag string, t reflect.Type) (*fieldRules, error) {
+	result := &fieldRules{checks: make([]check, 0, strings.Count(tag, "|")+1)}
+	for _, expr := range splitRules(tag) {
+		if err := result.add(expr, t); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// add разбирает одно правило тэга поля типа t.
+func (r *fieldRules) add(expr string, t reflect.Type) error {
+	elem := valueType(t)
+	name, arg, _ := strings.Cut(expr, ":")
+
+	if rule, ok := crossRules[name]; ok {
+		if arg == "" {
+			return fmt.Errorf("rule %q requires an argument: %w", name, ErrInvalidTag)
+		}
+		r.refs = append(r.refs, fieldRef{name: name, rule: rule, other: arg})
+		return nil
+	}
+	if fn, ok := customRule(name); ok {
+		var param interface{}
+		if arg != "" {
+			param = arg
+		}
+		r.checks = append(r.checks, func(v reflect.Value) error {
+			if err := fn(v, arg); err != nil {
+				return &RuleError{Rule: name, Param: param, Err: err}
+			}
+			return nil
+		})
+		return nil
+	}
+
+	var rules map[string]ruleFunc
+	switch {
+	case name == requiredDirective && arg == "":
+		r.required = true
+		return nil
+	case name == nestedDirective && arg == "" && elem.Kind() == reflect.Struct:
+		r.nested = true
+		return nil
+	case name == nestedDirective:
+		return fmt.Errorf("rule %q is not applicable to %s: %w", expr, t, ErrInvalidTag)
+	case name == requiredDirective:
+		return fmt.Errorf("rule %q takes no argument: %w", name, ErrInvalidTag)
+	case elem.Kind() == reflect.String:
+		rules = stringRules
+	case isInt(elem.Kind()):
+		rules = intRules
+	case isBuiltinRule(name) && elem.Kind() != reflect.Struct:
+		return fmt.Errorf("%s: %w", t, ErrUnsupportedType)
+	}
+
+	rule, ok := rules[name]
+	switch {
+	case !ok && isKnownRule(name):
+		return fmt.Errorf("rule %q is not applicable to %s: %w", name, t, ErrInvalidTag)
+	case !ok:
+		return fmt.Errorf("unknown rule %q: %w", name, ErrInvalidTag)
+	case arg == "":
+		return fmt.Errorf("rule %q requires an argument: %w", name, ErrInvalidTag)
+	}
+
+	c, err := rule(arg)
+	if err != nil {
+		return fmt.Errorf("rule %q: %w", expr, err)
+	}
+	r.checks = append(r.checks, c)
+	return nil
+}
+
+// valueType снимает с t указатели, слайсы, массивы и map до типа проверяемых значений.
+func valueType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() { //nolint:exhaustive // Остальные типы проверяются как есть
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// splitRules делит тэг по "|". Разделителем считается только "|", за которым
+// идёт имя известного правила, поэтому "|" можно использовать в regexp:
+// "regexp:^(admin|user)$|len:5" — два правила.
+func splitRules(tag string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == '|' && startsWithRule(tag[i+1:]) {
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+func startsWithRule(s string) bool {
+	end := strings.IndexAny(s, ":|")
+	if end < 0 {
+		end = len(s)
+	}
+	return isKnownRule(s[:end])
+}
+
+func isKnownRule(name string) bool {
+	if _, ok := crossRules[name]; ok {
+		return true
+	}
+	if _, ok := customRule(name); ok {
+		return true
+	}
+	return isBuiltinRule(name)
+}
+
+// isBuiltinRule сообщает, что имя занято встроенным правилом или директивой.
+func isBuiltinRule(name string) bool {
+	_, isString := stringRules[name]
+	_, isInt := intRules[name]
+	return isString || isInt || name == requiredDirective || name == nestedDirective
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+// lenRule — длина строки в символах ровно arg.
+func lenRule(arg string) (check, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("length must be a non-negative integer: %w", ErrInvalidTag)
+	}
+	return func(v reflect.Value) error { return CheckLen(v.String(), n) }, nil
+}
+
+// regexpRule — строка соответствует регулярному выражению.
+func regexpRule(arg string) (check, error) {
+	re, err := regexp.Compile(arg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTag, err)
+	}
+	return func(v reflect.Value) error { return CheckRegexp(v.String(), re) }, nil
+}
+
+// stringInRule — строка входит в список через запятую.
+func stringInRule(arg string) (check, error) {
+	allowed := strings.Split(arg, ",")
+	return func(v reflect.Value) error { return CheckStringIn(v.String(), allowed) }, nil
+}
+
+func minRule(arg string) (check, error) {
+	limit, err := parseInt(arg)
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value) error { return CheckMin(v.Int(), limit) }, nil
+}
+
+func maxRule(arg string) (check, error) {
+	limit, err := parseInt(arg)
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value) error { return CheckMax(v.Int(), limit) }, nil
+}
+
+// intInRule — число входит в список через запятую.
+func intInRule(arg string) (check, error) {
+	var allowed []int64
+	for _, s := range strings.Split(arg, ",") {
+		n, err := parseInt(s)
+		if err != nil {
+			return nil, err
+		}
+		allowed = append(allowed, n)
+	}
+	return func(v reflect.Value) error { return CheckIntIn(v.Int(), allowed) }, nil
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an integer: %w", s, ErrInvalidTag)
+	}
+	return n, nil
+}
@@ -1,17 +1,139 @@
 package hw09structvalidator
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Программные ошибки: неверное использование валидатора, а не невалидные данные.
+var (
+	ErrNotStruct       = errors.New("value is not a struct")
+	ErrInvalidTag      = errors.New("invalid validate tag")
+	ErrUnsupportedType = errors.New("unsupported field type")
+)
+
 type ValidationError struct {
 	Field string
 	Err   error
 }
 
+func (e ValidationError) Error() string {
+	// Ошибка Validatable самой проверяемой структуры не относится к полю
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
 type ValidationErrors []ValidationError
 
 func (v ValidationErrors) Error() string {
-	panic("implement me")
+	parts := make([]string, len(v))
+	for i, e := range v {
+		parts[i] = e.Error()
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap позволяет проверять ошибки отдельных полей через errors.Is.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
 }
 
+// Validate проверяет публичные поля структуры (или указателя на неё) по тэгу validate.
+// Возвращает ValidationErrors со всеми нарушениями или программную ошибку,
+// если тэг некорректен или тип поля не поддерживается.
+//
+// Поля с директивой nested проверяются рекурсивно; в ValidationError.Field
+// попадает полный путь до значения: Orders[2].Address.Zip. Поля встроенных
+// структур, как и в encoding/json, указываются без имени встроенного типа.
+// Кроме встроенных правил доступны межполевые (eqfield:Password, gtfield:Start)
+// и зарегистрированные через RegisterRule; после тэгов вызывается Validatable.
+// Разобранные тэги кэшируются по типу, Validate безопасна для конкурентного вызова.
 func Validate(v interface{}) error {
-	// Place your code here.
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%T: %w", v, ErrNotStruct)
+	}
+
+	var errs ValidationErrors
+	if err := planFor(rv.Type()).validate("", rv, &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateField проверяет значение поля по его правилам.
+func validateField(path string, v reflect.Value, rules *fieldRules, errs *ValidationErrors) error {
+	if rules.required && v.IsZero() {
+		*errs = append(*errs, ValidationError{Field: path, Err: ErrRequired})
+		return nil
+	}
+	return validateValue(path, v, rules, errs)
+}
+
+// validateValue спускается через указатели, слайсы и значения map
+// до структур (при nested) и значений, к которым применяются проверки.
+func validateValue(path string, v reflect.Value, rules *fieldRules, errs *ValidationErrors) error {
+	switch v.Kind() { //nolint:exhaustive // Остальные типы проверяются правилами
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return validateValue(path, v.Elem(), rules, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(path+"["+strconv.Itoa(i)+"]", v.Index(i), rules, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			if err := validateValue(fmt.Sprintf("%s[%v]", path, key), v.MapIndex(key), rules, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		runChecks(path, v, rules.checks, errs)
+		if rules.nested {
+			prefix := path
+			if prefix != "" {
+				prefix += "."
+			}
+			return planFor(v.Type()).validate(prefix, v, errs)
+		}
+	default:
+		runChecks(path, v, rules.checks, errs)
+	}
 	return nil
 }
+
+func runChecks(path string, v reflect.Value, checks []check, errs *ValidationErrors) {
+	for _, c := range checks {
+		if err := c(v); err != nil {
+			*errs = append(*errs, ValidationError{Field: path, Err: err})
+		}
+	}
+}
@@ -2,8 +2,11 @@ package hw09structvalidator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 type UserRole string
@@ -36,16 +39,152 @@ type (
 	}
 )
 
+// Структуры с ошибками в тэгах и типах полей.
+type (
+	Account struct {
+		Login  string `validate:"regexp:^(admin|user)\\d*$|len:6"`
+		Scores []int  `validate:"min:0|max:100"`
+		hidden string `validate:"len:100"` //nolint:unused
+	}
+
+	LenOnInt struct {
+		Age int `validate:"len:2"`
+	}
+
+	UnknownRule struct {
+		Name string `validate:"size:2"`
+	}
+
+	BadRegexp struct {
+		Name string `validate:"regexp:[a-"`
+	}
+
+	BadNumber struct {
+		Age int `validate:"min:ten"`
+	}
+
+	MissingArgument struct {
+		Name string `validate:"len"`
+	}
+
+	Unsupported struct {
+		Price float64 `validate:"min:0"`
+	}
+)
+
+// Вложенные структуры.
+type (
+	Address struct {
+		City string `validate:"len:3"`
+		Zip  string `validate:"regexp:^\\d{6}$"`
+	}
+
+	Order struct {
+		ID       int      `validate:"min:1"`
+		Address  *Address `validate:"required|nested"`
+		Comment  *string  `validate:"len:5"`
+		Discount *int     `validate:"max:50"`
+	}
+
+	Audit struct {
+		Author string `validate:"len:5"`
+	}
+
+	Customer struct {
+		Audit     `validate:"nested"`
+		Name      string             `validate:"required"`
+		Home      Address            `validate:"nested"`
+		Orders    []Order            `validate:"nested"`
+		Addresses map[string]Address `validate:"nested"`
+		Tags      map[string]string  `validate:"len:2"`
+		Manager   *Customer          `validate:"nested"`
+	}
+
+	// signature не экспортируется, поэтому встроенная в Letter не проверяется.
+	signature struct {
+		Author string `validate:"len:5"`
+	}
+
+	Letter struct {
+		signature `validate:"nested"`
+		Address   `validate:"nested"`
+	}
+
+	NestedOnString struct {
+		Name string `validate:"nested"`
+	}
+
+	LenOnStruct struct {
+		Home Address `validate:"len:3"`
+	}
+
+	RequiredWithArgument struct {
+		Home *Address `validate:"required:true"`
+	}
+)
+
+func validUser() User {
+	return User{
+		ID:     "123e4567-e89b-12d3-a456-426614174000",
+		Age:    30,
+		Email:  "user@example.com",
+		Role:   "admin",
+		Phones: []string{"79001234567", "79007654321"},
+	}
+}
+
 func TestValidate(t *testing.T) {
+	invalidUser := validUser()
+	invalidUser.ID = "short"
+	invalidUser.Age = 51
+	invalidUser.Email = "not an email"
+	invalidUser.Role = "guest"
+	invalidUser.Phones = []string{"79001234567", "123"}
+
 	tests := []struct {
 		in          interface{}
 		expectedErr error
 	}{
+		{in: validUser()},
+		{in: &App{Version: "1.0.0"}},
+		{in: Token{Header: []byte("header")}},
+		{in: Response{Code: 404}},
+		{in: Account{Login: "admin1", Scores: []int{0, 50, 100}}},
+		{
+			in: invalidUser,
+			expectedErr: ValidationErrors{
+				{Field: "ID", Err: ErrLen},
+				{Field: "Age", Err: ErrMax},
+				{Field: "Email", Err: ErrRegexp},
+				{Field: "Role", Err: ErrIn},
+				{Field: "Phones[1]", Err: ErrLen},
+			},
+		},
+		{
+			in:          App{Version: "1.0"},
+			expectedErr: ValidationErrors{{Field: "Version", Err: ErrLen}},
+		},
 		{
-			// Place your code here.
+			in:          Response{Code: 201},
+			expectedErr: ValidationErrors{{Field: "Code", Err: ErrIn}},
 		},
-		// ...
-		// Place your code here.
+		{
+			in: Account{Login: "guest", Scores: []int{-1, 101}},
+			expectedErr: ValidationErrors{
+				{Field: "Login", Err: ErrRegexp},
+				{Field: "Login", Err: ErrLen},
+				{Field: "Scores[0]", Err: ErrMin},
+				{Field: "Scores[1]", Err: ErrMax},
+			},
+		},
+		{in: "string", expectedErr: ErrNotStruct},
+		{in: (*User)(nil), expectedErr: ErrNotStruct},
+		{in: LenOnInt{}, expectedErr: ErrInvalidTag},
+		{in: UnknownRule{}, expectedErr: ErrInvalidTag},
+		{in: BadRegexp{}, expectedErr: ErrInvalidTag},
+		{in: BadNumber{}, expectedErr: ErrInvalidTag},
+		{in: MissingArgument{}, expectedErr: ErrInvalidTag},
+		{in: Unsupported{}, expectedErr: ErrUnsupportedType},
 	}
 
 	for i, tt := range tests {
@@ -53,8 +192,138 @@ func TestValidate(t *testing.T) {
 			tt := tt
 			t.Parallel()
 
-			// Place your code here.
-			_ = tt
+			err := Validate(tt.in)
+			requireValidationResult(t, tt.expectedErr, err)
+		})
+	}
+}
+
+// requireValidationResult сравнивает ошибки по полям и errors.Is, а не по тексту.
+func requireValidationResult(t *testing.T, expected, actual error) {
+	t.Helper()
+
+	var expectedErrs ValidationErrors
+	if !errors.As(expected, &expectedErrs) {
+		if expected == nil {
+			require.NoError(t, actual)
+		} else {
+			require.ErrorIs(t, actual, expected)
+			require.False(t, errors.As(actual, &ValidationErrors{}), "program error expected, got %v", actual)
+		}
+		return
+	}
+
+	var actualErrs ValidationErrors
+	require.ErrorAs(t, actual, &actualErrs)
+	require.Len(t, actualErrs, len(expectedErrs), actual.Error())
+	for i := range expectedErrs {
+		require.Equal(t, expectedErrs[i].Field, actualErrs[i].Field)
+		require.ErrorIs(t, actualErrs[i].Err, expectedErrs[i].Err)
+	}
+}
+
+func validCustomer() Customer {
+	return Customer{
+		Audit: Audit{Author: "admin"},
+		Name:  "Ivan",
+		Home:  Address{City: "Msk", Zip: "101000"},
+		Orders: []Order{
+			{ID: 1, Address: &Address{City: "Spb", Zip: "190000"}},
+		},
+		Addresses: map[string]Address{"work": {City: "Tvr", Zip: "170000"}},
+		Tags:      map[string]string{"lang": "ru"},
+	}
+}
+
+func TestValidateNested(t *testing.T) {
+	comment, discount := "short comment", 60
+	invalid := validCustomer()
+	invalid.Author = "bot"
+	invalid.Name = ""
+	invalid.Home.Zip = "abc"
+	invalid.Orders = []Order{
+		{ID: 1, Address: &Address{City: "Spb", Zip: "190000"}},
+		{ID: 0},
+		{ID: 3, Address: &Address{City: "Moscow", Zip: "1"}, Comment: &comment, Discount: &discount},
+	}
+	invalid.Addresses = map[string]Address{
+		"work": {City: "Tver", Zip: "170000"},
+		"home": {City: "Msk", Zip: "x"},
+	}
+	invalid.Tags = map[string]string{"lang": "rus"}
+	invalid.Manager = &Customer{Name: "Boss", Home: Address{City: "Msk", Zip: "101000"}}
+
+	tests := []struct {
+		in          interface{}
+		expectedErr error
+	}{
+		{in: validCustomer()},
+		{in: &Customer{Audit: Audit{Author: "admin"}, Name: "Ivan", Home: Address{City: "Msk", Zip: "101000"}}},
+		{
+			in: invalid,
+			expectedErr: ValidationErrors{
+				{Field: "Author", Err: ErrLen},
+				{Field: "Name", Err: ErrRequired},
+				{Field: "Home.Zip", Err: ErrRegexp},
+				{Field: "Orders[1].ID", Err: ErrMin},
+				{Field: "Orders[1].Address", Err: ErrRequired},
+				{Field: "Orders[2].Address.City", Err: ErrLen},
+				{Field: "Orders[2].Address.Zip", Err: ErrRegexp},
+				{Field: "Orders[2].Comment", Err: ErrLen},
+				{Field: "Orders[2].Discount", Err: ErrMax},
+				{Field: "Addresses[home].Zip", Err: ErrRegexp},
+				{Field: "Addresses[work].City", Err: ErrLen},
+				{Field: "Tags[lang]", Err: ErrLen},
+				{Field: "Manager.Author", Err: ErrLen},
+			},
+		},
+		{
+			in:          Letter{signature: signature{Author: "bot"}, Address: Address{City: "Msk", Zip: "x"}},
+			expectedErr: ValidationErrors{{Field: "Zip", Err: ErrRegexp}},
+		},
+		{in: NestedOnString{}, expectedErr: ErrInvalidTag},
+		{in: LenOnStruct{}, expectedErr: ErrInvalidTag},
+		{in: RequiredWithArgument{}, expectedErr: ErrInvalidTag},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(tt.in)
+			requireValidationResult(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := Validate(App{Version: "1"})
+	require.EqualError(t, err, "validation failed: Version: invalid length: must be 5, got 1")
+	require.ErrorIs(t, err, ErrLen)
+
+	err = Validate(Response{Code: 1})
+	require.EqualError(t, err, "validation failed: Code: not in allowed values 200,404,500, got 1")
+
+	err = Validate(BadNumber{})
+	require.EqualError(t, err, `field Age: rule "min:ten": "ten" is not an integer: invalid validate tag`)
+}
+
+func TestSplitRules(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{tag: "len:5", expected: []string{"len:5"}},
+		{tag: "min:0|max:10", expected: []string{"min:0", "max:10"}},
+		{tag: "regexp:^(a|b)$|len:1", expected: []string{"regexp:^(a|b)$", "len:1"}},
+		{tag: "regexp:a|in|len:1", expected: []string{"regexp:a", "in", "len:1"}},
+		{tag: "in:a|b", expected: []string{"in:a|b"}},
+		{tag: "required|nested", expected: []string{"required", "nested"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.tag, func(t *testing.T) {
+			require.Equal(t, tc.expected, splitRules(tc.tag))
 		})
 	}
 }
@@ -1,11 +1,14 @@
 package hw10programoptimization
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 type User struct {
@@ -20,47 +23,184 @@ type User struct {
 
 type DomainStat map[string]int
 
+// maxLineSize — предельная длина строки с одним пользователем.
+const maxLineSize = 1 << 20
+
+var errInvalidJSON = errors.New("invalid JSON")
+
+// GetDomainStat построчно читает пользователей из r и считает email-домены,
+// оканчивающиеся на домен первого уровня domain. Ключи — домены в нижнем регистре.
 func GetDomainStat(r io.Reader, domain string) (DomainStat, error) {
-	u, err := getUsers(r)
-	if err != nil {
+	// Счётчики по указателю: поиск по string(host) не выделяет память,
+	// и строка ключа создаётся только для нового домена
+	counts := make(map[string]*int)
+	suffix := []byte("." + domain)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		// Из JSON нужен только Email, поэтому User целиком не разбирается
+		email, err := emailField(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("get users error: line %d: %w", line, err)
+		}
+
+		host, ok := emailDomain(email, suffix)
+		if !ok {
+			continue
+		}
+		key := lowerASCII(host)
+		if n, ok := counts[string(key)]; ok {
+			*n++
+		} else {
+			n := 1
+			counts[string(key)] = &n
+		}
+	}
+	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("get users error: %w", err)
 	}
-	return countDomains(u, domain)
+
+	result := make(DomainStat, len(counts))
+	for host, n := range counts {
+		result[host] = *n
+	}
+	return result, nil
 }
 
-type users [100_000]User
+// emailDomain возвращает домен email, если он оканчивается на suffix без учёта регистра.
+func emailDomain(email, suffix []byte) ([]byte, bool) {
+	at := bytes.LastIndexByte(email, '@')
+	if at < 0 {
+		return nil, false
+	}
+	host := email[at+1:]
+	if len(host) <= len(suffix) || !bytes.EqualFold(host[len(host)-len(suffix):], suffix) {
+		return nil, false
+	}
+	return host, true
+}
 
-func getUsers(r io.Reader) (result users, err error) {
-	content, err := io.ReadAll(r)
-	if err != nil {
-		return
+// lowerASCII переводит b в нижний регистр на месте. Не-ASCII домен
+// переводится через strings.ToLower в новый срез.
+func lowerASCII(b []byte) []byte {
+	for i, c := range b {
+		switch {
+		case c >= utf8.RuneSelf:
+			return []byte(strings.ToLower(string(b)))
+		case 'A' <= c && c <= 'Z':
+			b[i] = c + 'a' - 'A'
+		}
 	}
+	return b
+}
 
-	lines := strings.Split(string(content), "\n")
-	for i, line := range lines {
-		var user User
-		if err = json.Unmarshal([]byte(line), &user); err != nil {
-			return
+// emailField возвращает строковое значение ключа Email верхнего уровня
+// JSON-объекта data или nil, если ключа нет. Значение без escape-последовательностей
+// указывает в data.
+func emailField(data []byte) ([]byte, error) {
+	if !json.Valid(data) {
+		return nil, errInvalidJSON
+	}
+
+	// data уже проверен, поэтому дальше разбор не проверяет синтаксис
+	i := skipSpace(data, 0)
+	if data[i] != '{' {
+		return nil, nil
+	}
+	i = skipSpace(data, i+1)
+	for data[i] == '"' {
+		end := stringEnd(data, i)
+		key := data[i:end]
+		i = skipSpace(data, end)
+		i = skipSpace(data, i+1) // ':'
+
+		if data[i] == '"' && isEmailKey(key) {
+			value := data[i:stringEnd(data, i)]
+			return unquote(value)
+		}
+		i = skipSpace(data, skipValue(data, i))
+		if data[i] == ',' {
+			i = skipSpace(data, i+1)
 		}
-		result[i] = user
 	}
-	return
+	return nil, nil
 }
 
-func countDomains(u users, domain string) (DomainStat, error) {
-	result := make(DomainStat)
+// isEmailKey сравнивает ключ в кавычках с "Email".
+func isEmailKey(key []byte) bool {
+	if bytes.Equal(key, []byte(`"Email"`)) {
+		return true
+	}
+	if bytes.IndexByte(key, '\\') < 0 {
+		return false
+	}
+	s, err := unquote(key)
+	return err == nil && string(s) == "Email"
+}
 
-	for _, user := range u {
-		matched, err := regexp.Match("\\."+domain, []byte(user.Email))
-		if err != nil {
-			return nil, err
+// unquote возвращает содержимое JSON-строки s в кавычках.
+func unquote(s []byte) ([]byte, error) {
+	if bytes.IndexByte(s, '\\') < 0 {
+		return s[1 : len(s)-1], nil
+	}
+	var v string
+	if err := json.Unmarshal(s, &v); err != nil {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+// skipSpace возвращает индекс первого непробельного символа начиная с i.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// stringEnd возвращает индекс за закрывающей кавычкой строки, начатой в i.
+func stringEnd(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
 		}
+	}
+	return len(data)
+}
 
-		if matched {
-			num := result[strings.ToLower(strings.SplitN(user.Email, "@", 2)[1])]
-			num++
-			result[strings.ToLower(strings.SplitN(user.Email, "@", 2)[1])] = num
+// skipValue возвращает индекс за значением, начатым в i.
+func skipValue(data []byte, i int) int {
+	switch data[i] {
+	case '"':
+		return stringEnd(data, i)
+	case '{', '[':
+		depth := 0
+		for ; i < len(data); i++ {
+			switch data[i] {
+			case '"':
+				i = stringEnd(data, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
 		}
+		return i
+	default:
+		// Число, true, false или null
+		for i < len(data) && strings.IndexByte(" \t\r\n,}]", data[i]) < 0 {
+			i++
+		}
+		return i
 	}
-	return result, nil
 }
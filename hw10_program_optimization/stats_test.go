@@ -1,9 +1,13 @@
+//go:build !bench
 // +build !bench
 
 package hw10programoptimization
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -37,3 +41,83 @@ func TestGetDomainStat(t *testing.T) {
 		require.Equal(t, DomainStat{}, result)
 	})
 }
+
+func TestGetDomainStatSuffix(t *testing.T) {
+	data := `{"Id":1,"Email":"a@mail.company.org"}
+{"Id":2,"Email":"b@com.net"}
+{"Id":3,"Email":"c@Example.COM"}
+{"Id":4,"Email":"d@examplecom"}
+{"Id":5,"Email":"no-at-sign.com"}
+{"Id":6,"Name":"Without Email"}
+
+{"Id":7,"Email":"e@sub.example.com","Name":"\"Email\":\"x@fake.com\""}
+{"Id":8,"Email":"f\u0040unicode.com"}
+{"Id":9,"Tags":["x",{"Email":"g@nested.com"}],"Meta":{"a":[1,{"b":"}"}]},"Email":"h@after.com"}
+{"Id":10, "Email" : null, "Active":true}
+{"Id":11,"Em\u0061il":"i@escaped-key.com"}
+`
+	result, err := GetDomainStat(strings.NewReader(data), "com")
+	require.NoError(t, err)
+	require.Equal(t, DomainStat{
+		"example.com":     1,
+		"sub.example.com": 1,
+		"unicode.com":     1,
+		"after.com":       1,
+		"escaped-key.com": 1,
+	}, result)
+
+	result, err = GetDomainStat(strings.NewReader(data), "COM")
+	require.NoError(t, err)
+	require.Len(t, result, 5, "domain is case-insensitive")
+
+	result, err = GetDomainStat(strings.NewReader(data), "c.org")
+	require.NoError(t, err)
+	require.Equal(t, DomainStat{}, result, "domain is not a regexp")
+}
+
+func TestGetDomainStatErrors(t *testing.T) {
+	_, err := GetDomainStat(strings.NewReader("{\"Email\":\"a@b.com\"}\n{broken"), "com")
+	require.ErrorContains(t, err, "line 2")
+
+	long := `{"Email":"a@b.com","Name":"` + strings.Repeat("x", maxLineSize) + `"}`
+	_, err = GetDomainStat(strings.NewReader(long), "com")
+	require.Error(t, err)
+}
+
+// usersReader генерирует n пользователей с сотней разных доменов .biz.
+func usersReader(n int) io.Reader {
+	readers := make([]io.Reader, 0, n)
+	for i := 0; i < n; i++ {
+		readers = append(readers, strings.NewReader(fmt.Sprintf(
+			`{"Id":%d,"Name":"User %[1]d","Username":"user%[1]d","Email":"user%[1]d@Domain%d.biz",`+
+				`"Phone":"6-866-899-36-79","Password":"InAQJvsq","Address":"Blackbird Place 25"}`+"\n", i, i%100)))
+	}
+	return io.MultiReader(readers...)
+}
+
+func TestGetDomainStatManyUsers(t *testing.T) {
+	const n = 100_001
+	result, err := GetDomainStat(usersReader(n), "biz")
+	require.NoError(t, err)
+	require.Len(t, result, 100)
+
+	total := 0
+	for _, count := range result {
+		total += count
+	}
+	require.Equal(t, n, total)
+}
+
+func BenchmarkGetDomainStat(b *testing.B) {
+	var data bytes.Buffer
+	_, err := io.Copy(&data, usersReader(100_000))
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetDomainStat(bytes.NewReader(data.Bytes()), "biz"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}